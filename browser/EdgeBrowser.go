@@ -1,10 +1,10 @@
 package browser
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
-	"time"
 
 	"slices"
 
@@ -14,83 +14,102 @@ import (
 type EdgeBrowser struct {
 	pw       *playwright.Playwright
 	port     int
-	browser  playwright.Browser
+	launcher Launcher
 	context  playwright.BrowserContext
+	harMode  HarMode
+	session  *SessionOptions
 	tabPages []*EdgeTabPage
 	locker   sync.Mutex
+	// tabsLocker 只保护 tabPages，并发打开和关闭详情标签页时 FindTabPage 等方法不持有 locker
+	tabsLocker sync.RWMutex
 }
 
-func newEdgeBrowser(edgePath string, startPort, endPort int) (*EdgeBrowser, error) {
-	// 1. 获取一个可用端口，用于调试
-	debugPort, err := getValidPort(startPort, endPort)
+func newEdgeBrowser(options *Options) (*EdgeBrowser, error) {
+	// 1. 根据启动方式选择启动器
+	launcher, err := newLauncher(options)
 	if err != nil {
-		return nil, fmt.Errorf("无法获取可用端口: %v", err)
+		return nil, err
 	}
 
 	// 2. 启动 Playwright
-	pw, err := playwright.Run()
+	var runOptions []*playwright.RunOptions
+	if options.Mode == LaunchChromium {
+		runOptions = append(runOptions, &playwright.RunOptions{Browsers: []string{"chromium"}})
+	}
+	if options.InstallDriver {
+		if err = playwright.Install(runOptions...); err != nil {
+			return nil, fmt.Errorf("无法安装 Playwright 驱动: %v", err)
+		}
+	}
+	pw, err := playwright.Run(runOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("无法启动 Playwright: %v", err)
 	}
 
-	// 3. 尝试连接到已运行的 Edge 实例
-	browser, found := connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
-	if !found {
-		// 如果没有找到已运行的 Edge 实例，则关闭所有 Edge 进程并启动一个新实例
-		err = killEdgeProcesses()
-		if err != nil {
-			pw.Stop()
-			return nil, fmt.Errorf("无法关闭 Edge 进程: %v", err)
-		}
+	// 3. 启动浏览器并获取上下文
+	browserContext, err := launcher.Launch(pw)
+	if err != nil {
+		pw.Stop()
+		return nil, err
+	}
 
-		// 启动新的 Edge 实例
-		_, err = startNewEdge(edgePath, fmt.Sprintf("%d", debugPort))
+	// 4. 关闭所有默认页面
+	pages := browserContext.Pages()
+	for _, p := range pages {
+		err = p.Close()
 		if err != nil {
-			pw.Stop()
-			return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
+			log.Printf("关闭页面失败: %v", err)
 		}
+	}
 
-		// 等待片刻，确保浏览器启动
-		time.Sleep(2 * time.Second)
-		log.Printf("Edge 浏览器已通过系统命令启动，调试端口: %d\n", debugPort)
-
-		// 连接到新启动的 Edge 实例
-		browser, found = connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
-		if !found {
+	// 5. 开启 HAR 录制/回放，HAR 在上下文关闭时导出，CDP 的默认上下文不能关闭，需要新建独立上下文
+	if options.HarMode != HarOff {
+		if options.Mode == LaunchEdgeCDP {
+			browserContext, err = browserContext.Browser().NewContext()
+			if err != nil {
+				launcher.Close()
+				pw.Stop()
+				return nil, fmt.Errorf("无法创建 HAR 上下文: %v", err)
+			}
+		}
+		if err = applyHar(browserContext, options); err != nil {
+			launcher.Close()
 			pw.Stop()
-			return nil, fmt.Errorf("无法连接到新启动的 Edge 实例")
+			return nil, err
 		}
-	} else {
-		log.Printf("已找到正在运行的 Edge 实例，调试端口:%d\n", debugPort)
 	}
 
-	// 4. 获取浏览器上下文并关闭所有默认页面
-	contexts := browser.Contexts()
-	if len(contexts) == 0 {
-		browser.Close()
+	// 6. 注册反检测脚本，在页面自身脚本运行前生效
+	stealth := options.Stealth
+	if stealth == nil {
+		stealth = DefaultStealthProfile()
+	}
+	if err = applyStealth(browserContext, stealth, launcher.DebugPort()); err != nil {
+		launcher.Close()
 		pw.Stop()
-		return nil, fmt.Errorf("未找到任何浏览器上下文")
+		return nil, err
 	}
-	browserContext := contexts[0]
-	pages := browserContext.Pages()
-	for _, p := range pages {
-		err = p.Close()
-		if err != nil {
-			log.Printf("关闭页面失败: %v", err)
-		}
+
+	// 7. 恢复上次保存的会话，跳过站点的反爬预热
+	if err = restoreSession(browserContext, options.Session); err != nil {
+		launcher.Close()
+		pw.Stop()
+		return nil, err
 	}
 
-	// 5. 创建 EdgeBrowser 实例
+	// 8. 创建 EdgeBrowser 实例
 	pe := &EdgeBrowser{
-		port:     debugPort,
+		port:     launcher.DebugPort(),
 		pw:       pw,
-		browser:  browser,
+		launcher: launcher,
 		context:  browserContext,
+		harMode:  options.HarMode,
+		session:  options.Session,
 		tabPages: make([]*EdgeTabPage, 0),
 		locker:   sync.Mutex{},
 	}
 
-	// 6. 创建默认标签页
+	// 9. 创建默认标签页
 	tabPage := pe.NewTabPage("default", "about:blank")
 	if tabPage == nil {
 		pe.Close()
@@ -102,12 +121,15 @@ func newEdgeBrowser(edgePath string, startPort, endPort int) (*EdgeBrowser, erro
 
 func (b *EdgeBrowser) addTabPage(id string, url string, page playwright.Page) *EdgeTabPage {
 	tabPage := newEdgeTabPage(id, url, b, page)
+	b.tabsLocker.Lock()
 	b.tabPages = append(b.tabPages, tabPage)
+	b.tabsLocker.Unlock()
 	return tabPage
 }
 
 func (b *EdgeBrowser) removeTabPage(id string) {
 	var tabPage *EdgeTabPage
+	b.tabsLocker.Lock()
 	for i, page := range b.tabPages {
 		if page.ID() == id {
 			tabPage = b.tabPages[i]
@@ -115,6 +137,7 @@ func (b *EdgeBrowser) removeTabPage(id string) {
 			break
 		}
 	}
+	b.tabsLocker.Unlock()
 
 	if tabPage != nil {
 		if !tabPage.page.IsClosed() {
@@ -123,7 +146,20 @@ func (b *EdgeBrowser) removeTabPage(id string) {
 	}
 }
 
+func (b *EdgeBrowser) V2() BrowserV2 {
+	return &EdgeBrowserV2{b}
+}
+
 func (b *EdgeBrowser) NewTabPage(id string, url string) TabPage {
+	tabPage, err := b.newTabPage(context.Background(), id, url)
+	if err != nil {
+		log.Printf("无法创建标签页 %s: %v", id, err)
+		return nil
+	}
+	return tabPage
+}
+
+func (b *EdgeBrowser) newTabPage(ctx context.Context, id string, url string) (*EdgeTabPage, error) {
 	b.locker.Lock()
 	defer b.locker.Unlock()
 
@@ -133,27 +169,36 @@ func (b *EdgeBrowser) NewTabPage(id string, url string) TabPage {
 	// 创建一个新的空白页面
 	page, err := b.context.NewPage()
 	if err != nil {
-		log.Printf("无法创建新页面: %v", err)
-		return nil
+		return nil, fmt.Errorf("无法创建新页面: %w", wrapError(err))
 	}
 
-	// 监听控制台消息
-	listen_page_console_log(page)
-
 	tabPage := b.addTabPage(id, url, page)
 
-	err = tabPage.Goto(url)
+	err = tabPage.gotoURL(ctx, url)
 	if err != nil {
 		b.removeTabPage(tabPage.id)
-		log.Printf("无法打开页面: %v", err)
-		return nil
+		return nil, fmt.Errorf("无法打开页面: %w", err)
 	}
 
-	return tabPage
+	return tabPage, nil
 }
 
 func (b *EdgeBrowser) FindTabPage(id string) TabPage {
-	for _, page := range b.tabPages {
+	if tabPage := b.findTabPage(id); tabPage != nil {
+		return tabPage
+	}
+	return nil
+}
+
+// tabPageList 返回标签页列表的副本
+func (b *EdgeBrowser) tabPageList() []*EdgeTabPage {
+	b.tabsLocker.RLock()
+	defer b.tabsLocker.RUnlock()
+	return slices.Clone(b.tabPages)
+}
+
+func (b *EdgeBrowser) findTabPage(id string) *EdgeTabPage {
+	for _, page := range b.tabPageList() {
 		if page.ID() == id {
 			return page
 		}
@@ -162,8 +207,9 @@ func (b *EdgeBrowser) FindTabPage(id string) TabPage {
 }
 
 func (b *EdgeBrowser) TabPages() []TabPage {
-	var tabPages []TabPage = make([]TabPage, 0, len(b.tabPages))
-	for _, page := range b.tabPages {
+	pages := b.tabPageList()
+	var tabPages []TabPage = make([]TabPage, 0, len(pages))
+	for _, page := range pages {
 		tabPages = append(tabPages, page)
 	}
 	return tabPages
@@ -192,17 +238,63 @@ func (b *EdgeBrowser) CloseTabPage(id string) error {
 	return nil
 }
 
+// SaveSession 立即保存配置的各站点会话，未配置 Options.Session 时不做任何事
+func (b *EdgeBrowser) SaveSession() error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.saveSession()
+}
+
+func (b *EdgeBrowser) saveSession() error {
+	// 回放时的 Cookie 来自 HAR，保存会覆盖真实访问得到的会话
+	if b.harMode == HarReplay {
+		return nil
+	}
+	tabPages := b.tabPageList()
+	pages := make([]playwright.Page, 0, len(tabPages))
+	for _, page := range tabPages {
+		pages = append(pages, page.page)
+	}
+	if err := saveSession(b.context, pages, b.session); err != nil {
+		return fmt.Errorf("保存会话失败: %w", wrapError(err))
+	}
+	return nil
+}
+
+// InvalidateSession 删除指定站点已保存的会话，当前浏览器中的数据需要用 ClearLocalData 清除
+func (b *EdgeBrowser) InvalidateSession(domain string) error {
+	if b.session == nil || b.session.Store == nil {
+		return fmt.Errorf("未配置会话存储")
+	}
+	InvalidateSession(b.session.Store, domain)
+	return nil
+}
+
 func (b *EdgeBrowser) Close() error {
 	b.locker.Lock()
 	defer b.locker.Unlock()
 
-	for _, page := range b.tabPages {
+	// 关闭标签页前保存会话，sessionStorage 只能从打开的标签页读取
+	if err := b.saveSession(); err != nil {
+		log.Printf("%v", err)
+	}
+
+	for _, page := range b.tabPageList() {
 		if !page.page.IsClosed() {
 			page.page.Close()
 		}
 	}
 
-	err := b.browser.Close()
+	// 关闭上下文时才会把录制的流量写入 HAR 文件
+	if b.harMode != HarOff {
+		if err := b.context.Close(); err != nil {
+			log.Printf("关闭浏览器上下文失败: %v", err)
+		} else if b.harMode == HarRecord {
+			log.Printf("网络流量已写入 HAR 文件")
+		}
+	}
+
+	err := b.launcher.Close()
 	if err != nil {
 		log.Printf("关闭浏览器失败: %v", err)
 		return err
@@ -0,0 +1,43 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+)
+
+// EdgeBrowserV2 是 EdgeBrowser 的 BrowserV2 视图，与 v1 共享同一个浏览器
+type EdgeBrowserV2 struct {
+	*EdgeBrowser
+}
+
+func (b *EdgeBrowserV2) V1() Browser {
+	return b.EdgeBrowser
+}
+
+func (b *EdgeBrowserV2) TabPages() []TabPageV2 {
+	pages := b.tabPageList()
+	var tabPages []TabPageV2 = make([]TabPageV2, 0, len(pages))
+	for _, page := range pages {
+		tabPages = append(tabPages, page.V2())
+	}
+	return tabPages
+}
+
+func (b *EdgeBrowserV2) NewTabPage(ctx context.Context, id string, url string) (TabPageV2, error) {
+	tabPage, err := b.newTabPage(ctx, id, url)
+	if err != nil {
+		return nil, err
+	}
+	return tabPage.V2(), nil
+}
+
+func (b *EdgeBrowserV2) FindTabPage(id string) (TabPageV2, error) {
+	tabPage := b.findTabPage(id)
+	if tabPage == nil {
+		return nil, fmt.Errorf("%w: 标签页 %s", ErrNotFound, id)
+	}
+	if tabPage.IsClosed() {
+		return nil, fmt.Errorf("%w: %s", ErrTabClosed, id)
+	}
+	return tabPage.V2(), nil
+}
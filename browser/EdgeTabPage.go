@@ -2,18 +2,27 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
 type EdgeTabPage struct {
-	id      string          // 标签页ID
-	url     string          // 标签页初始URL
-	browser *EdgeBrowser    // 浏览器实例
-	page    playwright.Page // 标签页实例
+	id             string              // 标签页ID
+	url            string              // 标签页初始URL
+	browser        *EdgeBrowser        // 浏览器实例
+	page           playwright.Page     // 标签页实例
+	recorders      []*ResponseRecorder // 响应记录器
+	recorderLocker sync.Mutex
+	listening      bool           // 是否已注册响应监听
+	consoleLog     []ConsoleEntry // 最近的控制台消息
+	networkLog     []NetworkEntry // 最近的网络请求
+	traceLocker    sync.Mutex
 }
 
 func newEdgeTabPage(id string, url string, browser *EdgeBrowser, page playwright.Page) *EdgeTabPage {
@@ -25,9 +34,27 @@ func newEdgeTabPage(id string, url string, browser *EdgeBrowser, page playwright
 		page:    page,
 	}
 
+	// 监听控制台消息和网络请求，供诊断使用
+	listen_page_console_log(tabPage)
+	listen_page_network(tabPage)
+
 	return tabPage
 }
 
+// ConsoleMessages 返回最近的控制台消息
+func (t *EdgeTabPage) ConsoleMessages() []ConsoleEntry {
+	t.traceLocker.Lock()
+	defer t.traceLocker.Unlock()
+	return slices.Clone(t.consoleLog)
+}
+
+// NetworkEntries 返回最近的网络请求
+func (t *EdgeTabPage) NetworkEntries() []NetworkEntry {
+	t.traceLocker.Lock()
+	defer t.traceLocker.Unlock()
+	return slices.Clone(t.networkLog)
+}
+
 func (t *EdgeTabPage) ID() string {
 	return t.id
 }
@@ -68,14 +95,49 @@ func (t *EdgeTabPage) Page() playwright.Page {
 	return t.page
 }
 
+func (t *EdgeTabPage) V2() TabPageV2 {
+	return &EdgeTabPageV2{t}
+}
+
+func (t *EdgeTabPage) checkOpen() error {
+	if t.page.IsClosed() {
+		return fmt.Errorf("%w: %s", ErrTabClosed, t.id)
+	}
+	return nil
+}
+
 func (t *EdgeTabPage) OpenInNewTab(id string, action func() error, timeout float64) TabPage {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+
+	tabPage, err := t.openInNewTab(ctx, id, action)
+	if err != nil {
+		log.Printf("打开新标签页失败: %v", err)
+		return nil
+	}
+	return tabPage
+}
+
+func (t *EdgeTabPage) openInNewTab(ctx context.Context, id string, action func() error) (*EdgeTabPage, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	timeout, err := contextTimeout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	t.browser.locker.Lock()
 	defer t.browser.locker.Unlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Millisecond)
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	newPageChan := make(chan playwright.Page, 1)
+	type result struct {
+		page playwright.Page
+		err  error
+	}
+	resultChan := make(chan result, 1)
 	done := make(chan struct{}) // 用于等待 goroutine 退出
 
 	go func() {
@@ -89,10 +151,10 @@ func (t *EdgeTabPage) OpenInNewTab(id string, action func() error, timeout float
 
 		newPage, err := t.browser.context.WaitForEvent("page", playwright.BrowserContextWaitForEventOptions{
 			Predicate: func(event any) bool { return true },
-			Timeout:   playwright.Float(timeout),
+			Timeout:   timeout,
 		})
 		if err != nil {
-			log.Printf("等待新页面失败: %v", err)
+			resultChan <- result{err: fmt.Errorf("等待新页面失败: %w", wrapError(err))}
 			return
 		}
 
@@ -101,14 +163,12 @@ func (t *EdgeTabPage) OpenInNewTab(id string, action func() error, timeout float
 			State: playwright.LoadStateDomcontentloaded,
 		}); err != nil {
 			newPageObj.Close()
+			resultChan <- result{err: fmt.Errorf("新页面加载失败: %w", wrapError(err))}
 			return
 		}
 
-		listen_page_console_log(newPageObj)
-		block_debug_port_detector(newPageObj, t.browser.port)
-
 		select {
-		case newPageChan <- newPageObj:
+		case resultChan <- result{page: newPageObj}:
 		case <-ctx.Done():
 			newPageObj.Close()
 		}
@@ -117,111 +177,152 @@ func (t *EdgeTabPage) OpenInNewTab(id string, action func() error, timeout float
 	if err := action(); err != nil {
 		cancel() // 取消上下文
 		<-done   // 等待 goroutine 退出
-		return nil
+		return nil, fmt.Errorf("触发新标签页失败: %w", wrapError(err))
 	}
 
 	select {
-	case newPage := <-newPageChan:
-		tabPage := t.browser.addTabPage(id, newPage.URL(), newPage)
+	case r := <-resultChan:
+		if r.err != nil {
+			return nil, r.err
+		}
+		tabPage := t.browser.addTabPage(id, r.page.URL(), r.page)
 		log.Printf("成功捕获新标签页, ID: %s", id)
-		return tabPage
+		return tabPage, nil
 	case <-ctx.Done():
 		<-done // 等待 goroutine 清理完毕
-		log.Printf("等待新标签页超时: %v", timeout)
-		return nil
+		return nil, fmt.Errorf("等待新标签页 %s 失败: %w", id, wrapError(ctx.Err()))
 	}
 }
 
-func (t *EdgeTabPage) WaitSelector(selector string, timeout float64) playwright.Locator {
-	locator := t.page.Locator(selector)
-	if locator == nil {
-		log.Printf("无法找到选择器: %s", selector)
-		return nil
-	}
-	// await expect(lolocator).toHaveCount(1)
+// single 确认 locator 恰好匹配一个元素
+func (t *EdgeTabPage) single(locator playwright.Locator, selector string) (playwright.Locator, error) {
 	count, err := locator.Count()
 	if err != nil {
-		log.Printf("无法获取选择器数量: %v", err)
-		return nil
+		return nil, fmt.Errorf("无法获取选择器数量: %w", wrapError(err))
 	}
 	if count == 0 {
-		log.Printf("选择器未匹配到任何元素: %s", selector)
-		return nil
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, selector)
 	}
 	if count > 1 {
+		return nil, fmt.Errorf("%w: %s 共 %d 个", ErrAmbiguous, selector, count)
+	}
+	return locator, nil
+}
+
+// WaitSelector 等待选择器可见，timeout 为毫秒，不大于 0 时与 v1 一样不限时
+func (t *EdgeTabPage) WaitSelector(selector string, timeout float64) playwright.Locator {
+	ctx := withoutTimeout(context.Background())
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	locator, err := t.waitSelector(ctx, selector)
+	if errors.Is(err, ErrAmbiguous) {
 		log.Printf("选择器匹配到多个元素: %s, 取第一条返回", selector)
-		locator = locator.First()
+		return t.page.Locator(selector).First()
 	}
-	err = locator.WaitFor(playwright.LocatorWaitForOptions{
-		State:   playwright.WaitForSelectorStateVisible,
-		Timeout: playwright.Float(timeout),
-	})
 	if err != nil {
+		log.Printf("等待选择器失败: %v", err)
 		return nil
 	}
 	return locator
 }
 
-func (t *EdgeTabPage) QuerySelector(selector string) playwright.Locator {
+func (t *EdgeTabPage) waitSelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
 	locator := t.page.Locator(selector)
-	if locator == nil {
-		log.Printf("无法找到选择器: %s", selector)
-		return nil
+	_, err := runWithContext(ctx, func(timeout *float64) (any, error) {
+		return nil, locator.First().WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: timeout,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("等待选择器 %s 失败: %w", selector, err)
+	}
+	return t.single(locator, selector)
+}
+
+func (t *EdgeTabPage) QuerySelector(selector string) playwright.Locator {
+	locator, err := t.querySelector(context.Background(), selector)
+	if errors.Is(err, ErrAmbiguous) {
+		log.Printf("选择器匹配到多个元素: %s, 取第一条返回", selector)
+		return t.page.Locator(selector).First()
 	}
-	count, err := locator.Count()
 	if err != nil {
-		log.Printf("无法获取选择器数量: %v", err)
+		log.Printf("查询选择器失败: %v", err)
 		return nil
 	}
-	if count == 0 {
-		log.Printf("选择器未匹配到任何元素: %s", selector)
-		return nil
+	return locator
+}
+
+func (t *EdgeTabPage) querySelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
 	}
-	if count > 1 {
-		log.Printf("选择器匹配到多个元素: %s, 取第一条返回", selector)
-		locator = locator.First()
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(err)
 	}
-	return locator
+	return t.single(t.page.Locator(selector), selector)
 }
 
 func (t *EdgeTabPage) QuerySelectorAll(selector string) []playwright.Locator {
-	locator := t.page.Locator(selector)
-	if locator == nil {
-		log.Printf("无法找到选择器: %s", selector)
+	items, err := t.querySelectorAll(context.Background(), selector)
+	if err != nil {
+		log.Printf("查询选择器失败: %v", err)
 		return []playwright.Locator{}
 	}
+	return items
+}
+
+func (t *EdgeTabPage) querySelectorAll(ctx context.Context, selector string) ([]playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	locator := t.page.Locator(selector)
 	count, err := locator.Count()
 	if err != nil {
-		log.Printf("无法获取选择器数量: %v", err)
-		return []playwright.Locator{}
+		return nil, fmt.Errorf("无法获取选择器数量: %w", wrapError(err))
 	}
 	if count == 0 {
-		log.Printf("选择器未匹配到任何元素: %s", selector)
-		return []playwright.Locator{}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, selector)
 	}
 	if count == 1 {
-		return []playwright.Locator{locator}
+		return []playwright.Locator{locator}, nil
 	}
 	items, err := locator.All()
 	if err != nil {
-		log.Printf("无法获取所有选择器: %v", err)
-		return []playwright.Locator{}
+		return nil, fmt.Errorf("无法获取所有选择器: %w", wrapError(err))
 	}
-	return items
+	return items, nil
 }
 
 func (t *EdgeTabPage) ClearLocalData() error {
+	return t.clearLocalData(context.Background())
+}
+
+func (t *EdgeTabPage) clearLocalData(ctx context.Context) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
 	log.Printf("正在清除站点%s的所有本地存储...", t.Domain())
-	if _, err := t.page.Evaluate("localStorage.clear()"); err != nil {
+	if _, err := t.evaluate(ctx, "localStorage.clear()"); err != nil {
 		return fmt.Errorf("清空 localStorage 失败: %w", err)
 	}
-	if _, err := t.page.Evaluate("sessionStorage.clear()"); err != nil {
+	if _, err := t.evaluate(ctx, "sessionStorage.clear()"); err != nil {
 		return fmt.Errorf("清空 sessionStorage 失败: %w", err)
 	}
 	if err := t.browser.context.ClearCookies(); err != nil {
-		return fmt.Errorf("清除 Cookies 失败: %w", err)
+		return fmt.Errorf("清除 Cookies 失败: %w", wrapError(err))
 	}
-	if _, err := t.page.Evaluate(`
+	if _, err := t.evaluate(ctx, `
         async () => {
             const databases = await window.indexedDB.databases();
             for (const db of databases) {
@@ -237,22 +338,33 @@ func (t *EdgeTabPage) ClearLocalData() error {
 }
 
 func (t *EdgeTabPage) Goto(url string) error {
+	return t.gotoURL(context.Background(), url)
+}
+
+func (t *EdgeTabPage) gotoURL(ctx context.Context, url string) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
 	// 导航
-	_, err := t.page.Goto(url, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	_, err := runWithContext(ctx, func(timeout *float64) (playwright.Response, error) {
+		return t.page.Goto(url, playwright.PageGotoOptions{
+			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+			Timeout:   timeout,
+		})
 	})
 	if err != nil {
-		return fmt.Errorf("无法访问网站: %v", err)
+		return fmt.Errorf("无法访问网站: %w", err)
 	}
 
-	block_debug_port_detector(t.page, t.browser.port)
-
 	// 等待页面完全加载
-	err = t.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State: playwright.LoadStateLoad,
+	_, err = runWithContext(ctx, func(timeout *float64) (any, error) {
+		return nil, t.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateLoad,
+			Timeout: timeout,
+		})
 	})
 	if err != nil {
-		return fmt.Errorf("等待页面加载失败: %v", err)
+		return fmt.Errorf("等待页面加载失败: %w", err)
 	}
 	log.Printf("已成功访问网站: %s", url)
 
@@ -260,5 +372,14 @@ func (t *EdgeTabPage) Goto(url string) error {
 }
 
 func (t *EdgeTabPage) Evaluate(expression string, arg ...any) (any, error) {
-	return t.page.Evaluate(expression, arg...)
+	return t.evaluate(context.Background(), expression, arg...)
+}
+
+func (t *EdgeTabPage) evaluate(ctx context.Context, expression string, arg ...any) (any, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	return runWithContext(ctx, func(_ *float64) (any, error) {
+		return t.page.Evaluate(expression, arg...)
+	})
 }
@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"context"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// EdgeTabPageV2 是 EdgeTabPage 的 TabPageV2 视图，与 v1 共享同一个标签页
+type EdgeTabPageV2 struct {
+	*EdgeTabPage
+}
+
+func (t *EdgeTabPageV2) V1() TabPage {
+	return t.EdgeTabPage
+}
+
+func (t *EdgeTabPageV2) OpenInNewTab(ctx context.Context, id string, action func() error) (TabPageV2, error) {
+	tabPage, err := t.openInNewTab(ctx, id, action)
+	if err != nil {
+		return nil, err
+	}
+	return tabPage.V2(), nil
+}
+
+func (t *EdgeTabPageV2) WaitSelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	return t.waitSelector(ctx, selector)
+}
+
+func (t *EdgeTabPageV2) QuerySelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	return t.querySelector(ctx, selector)
+}
+
+func (t *EdgeTabPageV2) QuerySelectorAll(ctx context.Context, selector string) ([]playwright.Locator, error) {
+	return t.querySelectorAll(ctx, selector)
+}
+
+func (t *EdgeTabPageV2) ClearLocalData(ctx context.Context) error {
+	return t.clearLocalData(ctx)
+}
+
+func (t *EdgeTabPageV2) Goto(ctx context.Context, url string) error {
+	return t.gotoURL(ctx, url)
+}
+
+func (t *EdgeTabPageV2) Evaluate(ctx context.Context, expression string, arg ...any) (any, error) {
+	return t.evaluate(ctx, expression, arg...)
+}
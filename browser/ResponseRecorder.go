@@ -0,0 +1,313 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const defaultRecorderBufferSize = 200
+
+// CapturedResponse 捕获到的一次网络响应
+type CapturedResponse struct {
+	Seq      int64             // 响应在标签页中到达的顺序号，从 1 开始
+	URL      string            // 请求地址
+	Method   string            // 请求方法
+	Status   int               // 响应状态码
+	Headers  map[string]string // 响应头（键为小写）
+	Body     []byte            // 原始响应体
+	JSON     any               // 响应体为 JSON 时解码后的内容，否则为 nil
+	Received time.Time         // 收到响应的时间
+}
+
+func (r *CapturedResponse) IsJSON() bool {
+	return r.JSON != nil
+}
+
+// DecodeResponse 将响应体解码为指定类型
+func DecodeResponse[T any](resp *CapturedResponse) (T, error) {
+	return ParseJson[T](string(resp.Body))
+}
+
+// ResponseRecorder 记录标签页中 URL 匹配指定模式的响应，响应按到达顺序逐条处理，
+// 可通过 Responses 读取缓冲区、通过 C 逐条接收，或用 WaitForResponse 等待某个操作触发的下一条响应
+type ResponseRecorder struct {
+	tab        *EdgeTabPage
+	patterns   []*regexp.Regexp
+	locker     sync.Mutex
+	buffer     []*CapturedResponse
+	bufferSize int
+	stream     chan *CapturedResponse
+	waiters    []responseWaiter
+	closed     bool
+	seq        int64
+	queue      []pendingResponse // 已到达、尚未读取响应体的响应，按到达顺序逐条处理
+	queued     chan struct{}     // 有新响应入队的信号
+	done       chan struct{}     // Close 后关闭，通知处理 goroutine 退出
+}
+
+// responseWaiter WaitForResponse 的等待者，只接收顺序号大于 after 的响应，
+// 即注册之后才到达的响应，避免拿到操作前已到达、还未处理完的响应
+type responseWaiter struct {
+	after int64
+	ch    chan *CapturedResponse
+}
+
+type pendingResponse struct {
+	seq      int64
+	response playwright.Response
+	received time.Time
+}
+
+// RecordResponses 开始记录 URL 匹配任一正则的响应，不传模式时记录所有响应
+func (t *EdgeTabPage) RecordResponses(patterns ...string) (*ResponseRecorder, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 URL 模式 %s: %w", pattern, err)
+		}
+		regexps = append(regexps, re)
+	}
+
+	r := newResponseRecorder(t, regexps)
+	// playwright 按函数指针移除监听，同一标签页只注册一次监听，再分发给各个记录器
+	t.recorderLocker.Lock()
+	defer t.recorderLocker.Unlock()
+	if !t.listening {
+		t.page.On("response", t.dispatchResponse)
+		t.listening = true
+	}
+	t.recorders = append(t.recorders, r)
+	return r, nil
+}
+
+func newResponseRecorder(t *EdgeTabPage, patterns []*regexp.Regexp) *ResponseRecorder {
+	r := &ResponseRecorder{
+		tab:        t,
+		patterns:   patterns,
+		bufferSize: defaultRecorderBufferSize,
+		stream:     make(chan *CapturedResponse, defaultRecorderBufferSize),
+		queued:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+func (t *EdgeTabPage) dispatchResponse(response playwright.Response) {
+	t.recorderLocker.Lock()
+	defer t.recorderLocker.Unlock()
+	for _, r := range t.recorders {
+		if r.match(response.URL()) {
+			r.enqueue(response)
+		}
+	}
+}
+
+func (t *EdgeTabPage) removeRecorder(recorder *ResponseRecorder) {
+	t.recorderLocker.Lock()
+	defer t.recorderLocker.Unlock()
+	t.recorders = slices.DeleteFunc(t.recorders, func(r *ResponseRecorder) bool { return r == recorder })
+}
+
+func (r *ResponseRecorder) match(url string) bool {
+	if len(r.patterns) == 0 {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue 在 playwright 的消息循环中调用，只记录到达顺序，不能阻塞
+func (r *ResponseRecorder) enqueue(response playwright.Response) {
+	r.locker.Lock()
+	if r.closed {
+		r.locker.Unlock()
+		return
+	}
+	r.seq++
+	r.queue = append(r.queue, pendingResponse{seq: r.seq, response: response, received: time.Now()})
+	r.locker.Unlock()
+	select {
+	case r.queued <- struct{}{}:
+	default:
+	}
+}
+
+// run 按到达顺序逐条读取响应体，读取响应体需要 playwright 的消息循环，因此不能在事件回调中进行
+func (r *ResponseRecorder) run() {
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.queued:
+		}
+		for {
+			r.locker.Lock()
+			if r.closed || len(r.queue) == 0 {
+				r.locker.Unlock()
+				break
+			}
+			pending := r.queue[0]
+			r.queue = r.queue[1:]
+			r.locker.Unlock()
+			r.capture(pending)
+		}
+	}
+}
+
+func (r *ResponseRecorder) capture(pending pendingResponse) {
+	response := pending.response
+	captured := &CapturedResponse{
+		Seq:      pending.seq,
+		URL:      response.URL(),
+		Method:   response.Request().Method(),
+		Status:   response.Status(),
+		Received: pending.received,
+	}
+	headers, err := response.AllHeaders()
+	if err != nil {
+		headers = response.Headers()
+	}
+	captured.Headers = headers
+
+	body, err := response.Body()
+	if err != nil {
+		// 重定向等响应没有响应体
+		log.Printf("读取响应体失败: %s, %v", captured.URL, err)
+	} else {
+		captured.Body = body
+		if strings.Contains(headers["content-type"], "json") || json.Valid(body) {
+			var decoded any
+			if err := json.Unmarshal(body, &decoded); err == nil {
+				captured.JSON = decoded
+			}
+		}
+	}
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	if r.closed {
+		return
+	}
+	r.buffer = append(r.buffer, captured)
+	if len(r.buffer) > r.bufferSize {
+		r.buffer = r.buffer[len(r.buffer)-r.bufferSize:]
+	}
+	select {
+	case r.stream <- captured:
+	default:
+		log.Printf("响应通道已满，丢弃: %s", captured.URL)
+	}
+	waiters := r.waiters[:0]
+	for _, waiter := range r.waiters {
+		if captured.Seq > waiter.after {
+			waiter.ch <- captured
+		} else {
+			waiters = append(waiters, waiter)
+		}
+	}
+	r.waiters = waiters
+}
+
+// C 返回逐条接收响应的通道，通道在 Close 后关闭
+func (r *ResponseRecorder) C() <-chan *CapturedResponse {
+	return r.stream
+}
+
+// Responses 返回当前缓冲区中的响应（最多保留最近的 200 条）
+func (r *ResponseRecorder) Responses() []*CapturedResponse {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	responses := make([]*CapturedResponse, len(r.buffer))
+	copy(responses, r.buffer)
+	return responses
+}
+
+// Last 返回最近一条响应，没有时返回 nil
+func (r *ResponseRecorder) Last() *CapturedResponse {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	if len(r.buffer) == 0 {
+		return nil
+	}
+	return r.buffer[len(r.buffer)-1]
+}
+
+func (r *ResponseRecorder) Clear() {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	r.buffer = nil
+}
+
+// WaitForResponse 执行 action（如点击分页按钮），并等待其后到达的下一条匹配响应，action 为 nil 时只等待；
+// 执行 action 前已到达（即使还在读取响应体）的响应不会返回
+func (r *ResponseRecorder) WaitForResponse(ctx context.Context, action func() error) (*CapturedResponse, error) {
+	r.locker.Lock()
+	if r.closed {
+		r.locker.Unlock()
+		return nil, fmt.Errorf("%w: 响应记录器已关闭", ErrTabClosed)
+	}
+	waiter := responseWaiter{after: r.seq, ch: make(chan *CapturedResponse, 1)}
+	r.waiters = append(r.waiters, waiter)
+	r.locker.Unlock()
+
+	if action != nil {
+		if err := action(); err != nil {
+			r.removeWaiter(waiter)
+			return nil, fmt.Errorf("执行操作失败: %w", wrapError(err))
+		}
+	}
+
+	select {
+	case captured := <-waiter.ch:
+		return captured, nil
+	case <-ctx.Done():
+		r.removeWaiter(waiter)
+		return nil, fmt.Errorf("等待响应失败: %w", wrapError(ctx.Err()))
+	}
+}
+
+func (r *ResponseRecorder) removeWaiter(waiter responseWaiter) {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	for i, w := range r.waiters {
+		if w.ch == waiter.ch {
+			r.waiters = append(r.waiters[:i], r.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close 停止记录并关闭通道
+func (r *ResponseRecorder) Close() {
+	// 先移除监听再加锁：分发响应时持有标签页的锁再获取记录器的锁，这里按相同顺序获取
+	r.tab.removeRecorder(r)
+
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.waiters = nil
+	r.queue = nil
+	close(r.done)
+	close(r.stream)
+}
@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// stubRequest 只实现记录器用到的方法，其余方法调用时 panic
+type stubRequest struct {
+	playwright.Request
+	method string
+}
+
+func (r *stubRequest) Method() string { return r.method }
+
+// stubResponse 只实现记录器用到的方法；release 不为 nil 时读取响应体会阻塞到 release 关闭，用于模拟响应体读取较慢
+type stubResponse struct {
+	playwright.Response
+	url     string
+	body    string
+	release chan struct{}
+}
+
+func (r *stubResponse) URL() string                 { return r.url }
+func (r *stubResponse) Status() int                 { return 200 }
+func (r *stubResponse) Request() playwright.Request { return &stubRequest{method: "GET"} }
+func (r *stubResponse) Headers() map[string]string  { return map[string]string{} }
+func (r *stubResponse) AllHeaders() (map[string]string, error) {
+	return map[string]string{"content-type": "application/json"}, nil
+}
+
+func (r *stubResponse) Body() ([]byte, error) {
+	if r.release != nil {
+		<-r.release
+	}
+	return []byte(r.body), nil
+}
+
+func newTestRecorder(t *testing.T, patterns ...string) (*EdgeTabPage, *ResponseRecorder) {
+	t.Helper()
+	tab := &EdgeTabPage{}
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regexps = append(regexps, regexp.MustCompile(pattern))
+	}
+	r := newResponseRecorder(tab, regexps)
+	tab.recorders = append(tab.recorders, r)
+	t.Cleanup(r.Close)
+	return tab, r
+}
+
+func TestResponseRecorderOrder(t *testing.T) {
+	tab, r := newTestRecorder(t, `/api/`)
+	// 第一条响应体读取较慢，后到达的响应仍按到达顺序处理
+	slow := &stubResponse{url: "https://example.com/api/1", body: `{"page":1}`, release: make(chan struct{})}
+	tab.dispatchResponse(slow)
+	tab.dispatchResponse(&stubResponse{url: "https://example.com/static/app.js", body: "js"})
+	tab.dispatchResponse(&stubResponse{url: "https://example.com/api/2", body: `{"page":2}`})
+	close(slow.release)
+
+	for i, want := range []string{"https://example.com/api/1", "https://example.com/api/2"} {
+		select {
+		case captured := <-r.C():
+			if captured.URL != want || captured.Seq != int64(i+1) {
+				t.Errorf("第 %d 条响应 = %s (Seq %d), want %s (Seq %d)", i+1, captured.URL, captured.Seq, want, i+1)
+			}
+			if !captured.IsJSON() {
+				t.Errorf("第 %d 条响应未解码 JSON: %s", i+1, captured.Body)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("等待第 %d 条响应超时", i+1)
+		}
+	}
+	if got := len(r.Responses()); got != 2 {
+		t.Errorf("Responses() 有 %d 条, want 2", got)
+	}
+	if last := r.Last(); last == nil || last.Seq != 2 {
+		t.Errorf("Last() = %v, want Seq 2", last)
+	}
+}
+
+// 执行操作前已到达、还在读取响应体的响应不能作为操作触发的响应返回
+func TestWaitForResponseSkipsEarlierResponses(t *testing.T) {
+	tab, r := newTestRecorder(t)
+	earlier := &stubResponse{url: "https://example.com/api/list?page=1", body: `{"page":1}`, release: make(chan struct{})}
+	tab.dispatchResponse(earlier)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	captured, err := r.WaitForResponse(ctx, func() error {
+		tab.dispatchResponse(&stubResponse{url: "https://example.com/api/list?page=2", body: `{"page":2}`})
+		close(earlier.release)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WaitForResponse() error = %v", err)
+	}
+	if captured.Seq != 2 || captured.URL != "https://example.com/api/list?page=2" {
+		t.Errorf("WaitForResponse() = %s (Seq %d), want 操作之后的第 2 条响应", captured.URL, captured.Seq)
+	}
+}
+
+func TestWaitForResponseErrors(t *testing.T) {
+	tab, r := newTestRecorder(t)
+	errClick := errors.New("click")
+	if _, err := r.WaitForResponse(context.Background(), func() error { return errClick }); !errors.Is(err, errClick) {
+		t.Errorf("操作失败时 WaitForResponse() error = %v, want %v", err, errClick)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.WaitForResponse(ctx, nil); !errors.Is(err, ErrTimeout) {
+		t.Errorf("没有响应时 WaitForResponse() error = %v, want %v", err, ErrTimeout)
+	}
+	r.locker.Lock()
+	waiters := len(r.waiters)
+	r.locker.Unlock()
+	if waiters != 0 {
+		t.Errorf("失败后仍有 %d 个等待者", waiters)
+	}
+
+	r.Close()
+	if _, err := r.WaitForResponse(context.Background(), nil); !errors.Is(err, ErrTabClosed) {
+		t.Errorf("关闭后 WaitForResponse() error = %v, want %v", err, ErrTabClosed)
+	}
+	if len(tab.recorders) != 0 {
+		t.Errorf("关闭后标签页仍有 %d 个记录器", len(tab.recorders))
+	}
+	// 关闭后到达的响应被忽略
+	tab.dispatchResponse(&stubResponse{url: "https://example.com/api/late"})
+	if _, ok := <-r.C(); ok {
+		t.Error("关闭后通道应已关闭")
+	}
+}
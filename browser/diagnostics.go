@@ -0,0 +1,174 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	maxConsoleEntries = 100 // 每个标签页保留的控制台消息数
+	maxNetworkEntries = 50  // 每个标签页保留的网络请求数
+)
+
+// ConsoleEntry 一条控制台消息
+type ConsoleEntry struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Text string    `json:"text"`
+}
+
+// NetworkEntry 一次网络请求，失败时 Status 为 0，Failure 为失败原因
+type NetworkEntry struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	URL     string    `json:"url"`
+	Status  int       `json:"status"`
+	Failure string    `json:"failure,omitempty"`
+}
+
+// traceable 能提供控制台消息和网络请求记录的标签页
+type traceable interface {
+	ConsoleMessages() []ConsoleEntry
+	NetworkEntries() []NetworkEntry
+}
+
+// diagnosticInfo 诊断包中的 info.json
+type diagnosticInfo struct {
+	RunID  string    `json:"run_id"`
+	TabID  string    `json:"tab_id"`
+	URL    string    `json:"url"`
+	Title  string    `json:"title"`
+	Reason string    `json:"reason"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^\p{Han}\w.-]+`)
+
+// NewRunID 生成本次运行的 ID，如 20250301-153000
+func NewRunID() string {
+	return time.Now().Format("20060102-150405")
+}
+
+// Diagnostics 将标签页的现场（截图、HTML、URL/标题、控制台消息、网络请求）写入 <logsDir>/<runID>/ 下的诊断包
+type Diagnostics struct {
+	dir    string
+	runID  string
+	seq    int
+	locker sync.Mutex
+}
+
+func NewDiagnostics(logsDir string, runID string) *Diagnostics {
+	if runID == "" {
+		runID = NewRunID()
+	}
+	return &Diagnostics{
+		dir:   filepath.Join(logsDir, runID),
+		runID: runID,
+	}
+}
+
+func (d *Diagnostics) RunID() string {
+	return d.runID
+}
+
+// Capture 为一个标签页写入诊断包，返回诊断包目录；cause 为导致诊断的错误，按需诊断时传 nil
+func (d *Diagnostics) Capture(tab TabPage, reason string, cause error) (string, error) {
+	d.locker.Lock()
+	d.seq++
+	seq := d.seq
+	d.locker.Unlock()
+
+	now := time.Now()
+	name := fmt.Sprintf("%03d-%s-%s", seq, unsafeFileChars.ReplaceAllString(tab.ID(), "_"), now.Format("150405"))
+	dir := filepath.Join(d.dir, name)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("无法创建诊断目录: %v", err)
+	}
+
+	info := diagnosticInfo{
+		RunID:  d.runID,
+		TabID:  tab.ID(),
+		Reason: reason,
+		Time:   now,
+	}
+	if cause != nil {
+		info.Error = cause.Error()
+	}
+
+	// 标签页关闭后只能保存控制台消息和网络请求
+	var errs []string
+	if !tab.IsClosed() {
+		page := tab.Page()
+		info.URL = page.URL()
+		info.Title = tab.Title()
+
+		if _, err := page.Screenshot(playwright.PageScreenshotOptions{
+			Path:     playwright.String(filepath.Join(dir, "screenshot.png")),
+			FullPage: playwright.Bool(true),
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("截图失败: %v", err))
+		}
+
+		if html, err := page.Content(); err != nil {
+			errs = append(errs, fmt.Sprintf("获取 HTML 失败: %v", err))
+		} else if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(html), 0644); err != nil {
+			errs = append(errs, fmt.Sprintf("保存 HTML 失败: %v", err))
+		}
+	}
+
+	if trace, ok := tab.(traceable); ok {
+		var console strings.Builder
+		for _, entry := range trace.ConsoleMessages() {
+			fmt.Fprintf(&console, "%s [%s] %s\n", entry.Time.Format("15:04:05.000"), entry.Type, entry.Text)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "console.log"), []byte(console.String()), 0644); err != nil {
+			errs = append(errs, fmt.Sprintf("保存控制台消息失败: %v", err))
+		}
+		if err := writeJsonFile(filepath.Join(dir, "network.json"), trace.NetworkEntries()); err != nil {
+			errs = append(errs, fmt.Sprintf("保存网络请求失败: %v", err))
+		}
+	}
+
+	if err := writeJsonFile(filepath.Join(dir, "info.json"), info); err != nil {
+		errs = append(errs, fmt.Sprintf("保存页面信息失败: %v", err))
+	}
+
+	log.Printf("已保存诊断信息: %s", dir)
+	if len(errs) > 0 {
+		return dir, fmt.Errorf("诊断信息不完整: %s", strings.Join(errs, "; "))
+	}
+	return dir, nil
+}
+
+// CaptureAll 为浏览器中的每个标签页各写入一个诊断包
+func (d *Diagnostics) CaptureAll(browser Browser, reason string, cause error) []string {
+	dirs := make([]string, 0)
+	for _, tab := range browser.TabPages() {
+		dir, err := d.Capture(tab, reason, cause)
+		if err != nil {
+			log.Printf("保存标签页 %s 的诊断信息失败: %v", tab.ID(), err)
+		}
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func writeJsonFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,96 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+var (
+	ErrNotFound  = errors.New("未找到匹配的元素")
+	ErrAmbiguous = errors.New("匹配到多个元素")
+	ErrTimeout   = errors.New("操作超时")
+	ErrTabClosed = errors.New("标签页已关闭")
+)
+
+// wrapError 将 playwright 和 context 的错误转换为本包的哨兵错误，便于调用方用 errors.Is 判断
+func wrapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrAmbiguous), errors.Is(err, ErrTimeout), errors.Is(err, ErrTabClosed):
+		return err
+	case errors.Is(err, playwright.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(err, playwright.ErrTargetClosed):
+		return fmt.Errorf("%w: %w", ErrTabClosed, err)
+	default:
+		return err
+	}
+}
+
+// noTimeoutKey 标记 ctx 没有截止时间时 playwright 也不限时，见 withoutTimeout
+type noTimeoutKey struct{}
+
+// withoutTimeout 返回的 ctx 没有截止时间时向 playwright 传 0（不限时），用于保持 v1 中 timeout 为 0 的行为
+func withoutTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+// contextTimeout 根据 ctx 的截止时间计算 playwright 的超时毫秒数，没有截止时间时返回 nil 使用 playwright 的默认值，
+// 由 withoutTimeout 创建的 ctx 返回 0 不限时
+func contextTimeout(ctx context.Context) (*float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		if ctx.Value(noTimeoutKey{}) != nil {
+			return playwright.Float(0), nil
+		}
+		return nil, nil
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 { // playwright 中 0 表示不限时，这里直接视为超时
+		return nil, wrapError(context.DeadlineExceeded)
+	}
+	return playwright.Float(max(float64(remaining.Milliseconds()), 1)), nil
+}
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+// runWithContext 执行 fn，ctx 的截止时间作为 playwright 的超时传给 fn。
+// playwright 的调用无法中途取消，fn 在单独的 goroutine 中执行，ctx 取消时立即返回 ctx 的错误；
+// 此时 fn 仍会执行到 playwright 的超时为止，结果写入带缓冲的 channel 后 goroutine 退出，不会泄漏
+func runWithContext[T any](ctx context.Context, fn func(timeout *float64) (T, error)) (T, error) {
+	var zero T
+	timeout, err := contextTimeout(ctx)
+	if err != nil {
+		return zero, err
+	}
+	done := make(chan result[T], 1)
+	go func() {
+		value, err := fn(timeout)
+		done <- result[T]{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			// fn 因 ctx 的截止时间超时时返回 ctx 的错误
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return zero, wrapError(fmt.Errorf("%w: %w", ctxErr, r.err))
+			}
+			return zero, wrapError(r.err)
+		}
+		return r.value, nil
+	case <-ctx.Done():
+		return zero, wrapError(ctx.Err())
+	}
+}
@@ -0,0 +1,146 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []error // 结果应能用 errors.Is 匹配的错误
+	}{
+		{"playwright 超时", fmt.Errorf("等待选择器: %w", playwright.ErrTimeout), []error{ErrTimeout, playwright.ErrTimeout}},
+		{"ctx 截止时间", context.DeadlineExceeded, []error{ErrTimeout, context.DeadlineExceeded}},
+		{"标签页已关闭", playwright.ErrTargetClosed, []error{ErrTabClosed, playwright.ErrTargetClosed}},
+		{"已是哨兵错误", fmt.Errorf("选择器 a: %w", ErrNotFound), []error{ErrNotFound}},
+		{"多个元素", ErrAmbiguous, []error{ErrAmbiguous}},
+		{"其他错误原样返回", errors.New("other"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapError(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("wrapError() = %v, 不再包含原错误", got)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(got, want) {
+					t.Errorf("wrapError() = %v, want errors.Is %v", got, want)
+				}
+			}
+		})
+	}
+	if wrapError(nil) != nil {
+		t.Error("wrapError(nil) != nil")
+	}
+}
+
+func TestContextTimeout(t *testing.T) {
+	deadline, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		want    *float64 // nil 表示使用 playwright 的默认超时
+		wantErr error
+	}{
+		{"没有截止时间", context.Background(), nil, nil},
+		{"v1 不限时", withoutTimeout(context.Background()), playwright.Float(0), nil},
+		{"截止时间优先于不限时", withoutTimeout(deadline), playwright.Float(60000), nil},
+		{"已过截止时间", expired, nil, ErrTimeout},
+		{"已取消", canceled, nil, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := contextTimeout(tt.ctx)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("contextTimeout() error = %v, want %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("contextTimeout() = %v, want nil", *got)
+			case tt.want != nil && got == nil:
+				t.Errorf("contextTimeout() = nil, want %v", *tt.want)
+			case tt.want != nil && (*got > *tt.want || *got < *tt.want-1000):
+				t.Errorf("contextTimeout() = %v, want about %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithContext(t *testing.T) {
+	t.Run("返回 fn 的结果", func(t *testing.T) {
+		got, err := runWithContext(context.Background(), func(timeout *float64) (int, error) { return 3, nil })
+		if err != nil || got != 3 {
+			t.Errorf("runWithContext() = %d, %v, want 3, nil", got, err)
+		}
+	})
+
+	t.Run("playwright 错误转换为哨兵错误", func(t *testing.T) {
+		_, err := runWithContext(context.Background(), func(timeout *float64) (int, error) {
+			return 0, playwright.ErrTargetClosed
+		})
+		if !errors.Is(err, ErrTabClosed) {
+			t.Errorf("runWithContext() error = %v, want %v", err, ErrTabClosed)
+		}
+	})
+
+	t.Run("截止时间作为 playwright 超时，超时后返回 ErrTimeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := runWithContext(ctx, func(timeout *float64) (int, error) {
+			if timeout == nil || *timeout > 50 {
+				return 0, fmt.Errorf("timeout = %v", timeout)
+			}
+			time.Sleep(time.Duration(*timeout) * time.Millisecond)
+			return 0, playwright.ErrTimeout
+		})
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("runWithContext() error = %v, want %v", err, ErrTimeout)
+		}
+	})
+
+	t.Run("没有截止时间的 ctx 取消后立即返回", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		release := make(chan struct{})
+		defer close(release)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		start := time.Now()
+		_, err := runWithContext(ctx, func(timeout *float64) (int, error) {
+			<-release // 模拟阻塞中的 playwright 调用
+			return 1, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("runWithContext() error = %v, want %v", err, context.Canceled)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("取消后 %v 才返回", elapsed)
+		}
+	})
+
+	t.Run("已取消的 ctx 不执行 fn", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := runWithContext(ctx, func(timeout *float64) (int, error) {
+			t.Error("fn 不应执行")
+			return 0, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("runWithContext() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"rpa-yjj-api/browser"
+)
+
+// Browser 基于 Site 夹具的假浏览器，实现 browser.Browser，不需要安装 Edge 或 Playwright
+type Browser struct {
+	site     *Site
+	tabPages []*TabPage
+	popups   []*TabPage // 已打开但还未被 OpenInNewTab 捕获的新标签页
+	opened   int        // 累计打开的新标签页数量
+	locker   sync.Mutex
+	// openLocker 与 Edge 一致，同一时间只有一个 OpenInNewTab 在捕获新标签页
+	openLocker sync.Mutex
+}
+
+// NewBrowser 创建假浏览器，与 browser.StartBrowser 一致，启动后带有一个 ID 为 default 的空白标签页
+func NewBrowser(site *Site) *Browser {
+	b := &Browser{
+		site:     site,
+		tabPages: make([]*TabPage, 0),
+	}
+	if _, err := b.newTabPage(context.Background(), "default", "about:blank"); err != nil {
+		panic(fmt.Sprintf("fake: %v", err))
+	}
+	return b
+}
+
+func (b *Browser) V2() browser.BrowserV2 {
+	return &BrowserV2{b}
+}
+
+func (b *Browser) NewTabPage(id string, url string) browser.TabPage {
+	tabPage, err := b.newTabPage(context.Background(), id, url)
+	if err != nil {
+		log.Printf("无法创建标签页 %s: %v", id, err)
+		return nil
+	}
+	return tabPage
+}
+
+func (b *Browser) newTabPage(ctx context.Context, id string, url string) (*TabPage, error) {
+	tabPage := newTabPage(id, b)
+	if err := tabPage.gotoURL(ctx, url); err != nil {
+		return nil, fmt.Errorf("无法打开页面: %w", err)
+	}
+
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	b.tabPages = append(b.tabPages, tabPage)
+	return tabPage, nil
+}
+
+// openPopup 模拟页面脚本打开新窗口，新标签页在被 claimPopup 认领前不属于 TabPages
+func (b *Browser) openPopup(url string) error {
+	tabPage := newTabPage("", b)
+	if err := tabPage.load(url); err != nil {
+		return err
+	}
+
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	b.popups = append(b.popups, tabPage)
+	b.opened++
+	return nil
+}
+
+func (b *Browser) popupCount() int {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.opened
+}
+
+// claimPopup 认领 before 之后打开的最近一个新标签页，没有时返回 nil
+func (b *Browser) claimPopup(id string, before int) *TabPage {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.opened <= before || len(b.popups) == 0 {
+		return nil
+	}
+	tabPage := b.popups[len(b.popups)-1]
+	b.popups = b.popups[:len(b.popups)-1]
+	tabPage.id = id
+	b.tabPages = append(b.tabPages, tabPage)
+	return tabPage
+}
+
+func (b *Browser) FindTabPage(id string) browser.TabPage {
+	if tabPage := b.findTabPage(id); tabPage != nil {
+		return tabPage
+	}
+	return nil
+}
+
+func (b *Browser) findTabPage(id string) *TabPage {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	for _, page := range b.tabPages {
+		if page.ID() == id {
+			return page
+		}
+	}
+	return nil
+}
+
+func (b *Browser) TabPages() []browser.TabPage {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	var tabPages []browser.TabPage = make([]browser.TabPage, 0, len(b.tabPages))
+	for _, page := range b.tabPages {
+		tabPages = append(tabPages, page)
+	}
+	return tabPages
+}
+
+func (b *Browser) SwitchToTabPage(id string) error {
+	tabPage := b.FindTabPage(id)
+	if tabPage == nil {
+		return fmt.Errorf("未找到标签页: %s", id)
+	}
+
+	tabPage.BringToFront()
+	return nil
+}
+
+func (b *Browser) CloseTabPage(id string) error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	for i, page := range b.tabPages {
+		if page.ID() == id {
+			page.close()
+			b.tabPages = slices.Delete(b.tabPages, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("未找到标签页: %s", id)
+}
+
+func (b *Browser) Close() error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	for _, page := range b.tabPages {
+		page.close()
+	}
+	for _, page := range b.popups {
+		page.close()
+	}
+	return nil
+}
+
+// BrowserV2 是 Browser 的 browser.BrowserV2 视图
+type BrowserV2 struct {
+	*Browser
+}
+
+func (b *BrowserV2) V1() browser.Browser {
+	return b.Browser
+}
+
+func (b *BrowserV2) TabPages() []browser.TabPageV2 {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	var tabPages []browser.TabPageV2 = make([]browser.TabPageV2, 0, len(b.tabPages))
+	for _, page := range b.tabPages {
+		tabPages = append(tabPages, page.V2())
+	}
+	return tabPages
+}
+
+func (b *BrowserV2) NewTabPage(ctx context.Context, id string, url string) (browser.TabPageV2, error) {
+	tabPage, err := b.newTabPage(ctx, id, url)
+	if err != nil {
+		return nil, err
+	}
+	return tabPage.V2(), nil
+}
+
+func (b *BrowserV2) FindTabPage(id string) (browser.TabPageV2, error) {
+	tabPage := b.findTabPage(id)
+	if tabPage == nil {
+		return nil, fmt.Errorf("%w: 标签页 %s", browser.ErrNotFound, id)
+	}
+	if tabPage.IsClosed() {
+		return nil, fmt.Errorf("%w: %s", browser.ErrTabClosed, id)
+	}
+	return tabPage.V2(), nil
+}
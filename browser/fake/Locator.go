@@ -0,0 +1,340 @@
+package fake
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/net/html"
+)
+
+const allNodes = -1 << 31 // nth 取该值时保留所有匹配的元素
+
+// Locator 实现采集脚本用到的 playwright.Locator 方法，其余方法见 unimplemented.go。
+// 与 Playwright 一致，定位器是惰性的，每次调用时才在当前文档中查找元素，
+// 操作单个元素的方法在匹配到多个元素时报错（strict mode）
+type Locator struct {
+	tab      *TabPage
+	parent   *Locator
+	selector string // 为空时表示对 parent 的结果取第 nth 个
+	nth      int    // 负数从末尾倒数，allNodes 表示不筛选
+	err      error  // 不为空时所有操作都返回该错误
+}
+
+func (l *Locator) String() string {
+	var text string
+	if l.parent != nil {
+		text = l.parent.String()
+	}
+	if l.selector != "" {
+		if text != "" {
+			text += " >> "
+		}
+		text += l.selector
+	}
+	if l.nth != allNodes {
+		text += " >> nth=" + strconv.Itoa(l.nth)
+	}
+	return text
+}
+
+// resolve 返回定位器当前匹配的元素，按文档顺序排列
+func (l *Locator) resolve() ([]*html.Node, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	if err := l.tab.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	var nodes []*html.Node
+	if l.parent == nil {
+		found, err := l.tab.query(nil, l.selector)
+		if err != nil {
+			return nil, err
+		}
+		nodes = found
+	} else {
+		roots, err := l.parent.resolve()
+		if err != nil {
+			return nil, err
+		}
+		if l.selector == "" {
+			nodes = roots
+		} else {
+			for _, root := range roots {
+				found, err := l.tab.query(root, l.selector)
+				if err != nil {
+					return nil, err
+				}
+				for _, n := range found {
+					if !slices.Contains(nodes, n) {
+						nodes = append(nodes, n)
+					}
+				}
+			}
+		}
+	}
+
+	if l.nth == allNodes {
+		return nodes, nil
+	}
+	index := l.nth
+	if index < 0 {
+		index += len(nodes)
+	}
+	if index < 0 || index >= len(nodes) {
+		return nil, nil
+	}
+	return nodes[index : index+1], nil
+}
+
+// resolveOne 要求定位器恰好匹配一个元素，夹具是静态的，未匹配时直接视为超时
+func (l *Locator) resolveOne() (*html.Node, error) {
+	nodes, err := l.resolve()
+	if err != nil {
+		return nil, err
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, fmt.Errorf("%w: 等待定位器 %s 失败", playwright.ErrTimeout, l)
+	case 1:
+		return nodes[0], nil
+	default:
+		return nil, fmt.Errorf("strict mode violation: 定位器 %s 匹配到 %d 个元素", l, len(nodes))
+	}
+}
+
+func (l *Locator) Locator(selectorOrLocator any, options ...playwright.LocatorLocatorOptions) playwright.Locator {
+	selector, ok := selectorOrLocator.(string)
+	if !ok {
+		return l.unsupportedLocator(fmt.Sprintf("Locator.Locator(%T)", selectorOrLocator))
+	}
+	return &Locator{tab: l.tab, parent: l, selector: selector, nth: allNodes}
+}
+
+func (l *Locator) Nth(index int) playwright.Locator {
+	return &Locator{tab: l.tab, parent: l, nth: index}
+}
+
+func (l *Locator) First() playwright.Locator {
+	return l.Nth(0)
+}
+
+func (l *Locator) Last() playwright.Locator {
+	return l.Nth(-1)
+}
+
+func (l *Locator) All() ([]playwright.Locator, error) {
+	nodes, err := l.resolve()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]playwright.Locator, 0, len(nodes))
+	for i := range nodes {
+		items = append(items, l.Nth(i))
+	}
+	return items, nil
+}
+
+func (l *Locator) Count() (int, error) {
+	nodes, err := l.resolve()
+	return len(nodes), err
+}
+
+func (l *Locator) InnerText(options ...playwright.LocatorInnerTextOptions) (string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return "", err
+	}
+	return innerText(n), nil
+}
+
+func (l *Locator) AllInnerTexts() ([]string, error) {
+	nodes, err := l.resolve()
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		texts = append(texts, innerText(n))
+	}
+	return texts, nil
+}
+
+func (l *Locator) TextContent(options ...playwright.LocatorTextContentOptions) (string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return "", err
+	}
+	return textContent(n), nil
+}
+
+func (l *Locator) InnerHTML(options ...playwright.LocatorInnerHTMLOptions) (string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return "", err
+	}
+	return innerHTML(n), nil
+}
+
+// GetAttribute 属性不存在时与 Playwright 一样返回空字符串
+func (l *Locator) GetAttribute(name string, options ...playwright.LocatorGetAttributeOptions) (string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return "", err
+	}
+	return attr(n, name), nil
+}
+
+func (l *Locator) InputValue(options ...playwright.LocatorInputValueOptions) (string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return "", err
+	}
+	if n.Data == "textarea" {
+		return textContent(n), nil
+	}
+	return attr(n, "value"), nil
+}
+
+func (l *Locator) IsVisible(options ...playwright.LocatorIsVisibleOptions) (bool, error) {
+	nodes, err := l.resolve()
+	if err != nil {
+		return false, err
+	}
+	return len(nodes) > 0 && !isHidden(nodes[0]), nil
+}
+
+func (l *Locator) IsHidden(options ...playwright.LocatorIsHiddenOptions) (bool, error) {
+	visible, err := l.IsVisible()
+	return !visible, err
+}
+
+// WaitFor 夹具是静态的，不满足状态时直接返回超时错误
+func (l *Locator) WaitFor(options ...playwright.LocatorWaitForOptions) error {
+	state := playwright.WaitForSelectorStateVisible
+	if len(options) > 0 && options[0].State != nil {
+		state = options[0].State
+	}
+	nodes, err := l.resolve()
+	if err != nil {
+		return err
+	}
+	if len(nodes) > 1 {
+		return fmt.Errorf("strict mode violation: 定位器 %s 匹配到 %d 个元素", l, len(nodes))
+	}
+
+	var ok bool
+	switch *state {
+	case *playwright.WaitForSelectorStateAttached:
+		ok = len(nodes) == 1
+	case *playwright.WaitForSelectorStateDetached:
+		ok = len(nodes) == 0
+	case *playwright.WaitForSelectorStateHidden:
+		ok = len(nodes) == 0 || isHidden(nodes[0])
+	default:
+		ok = len(nodes) == 1 && !isHidden(nodes[0])
+	}
+	if !ok {
+		return fmt.Errorf("%w: 等待定位器 %s 变为 %s 失败", playwright.ErrTimeout, l, *state)
+	}
+	return nil
+}
+
+// Click 触发 Site.OnClick 注册的行为
+func (l *Locator) Click(options ...playwright.LocatorClickOptions) error {
+	n, err := l.resolveOne()
+	if err != nil {
+		return err
+	}
+	return l.tab.dispatch("click", n)
+}
+
+// Fill 修改输入框的值，不触发任何行为
+func (l *Locator) Fill(value string, options ...playwright.LocatorFillOptions) error {
+	n, err := l.resolveOne()
+	if err != nil {
+		return err
+	}
+	l.tab.locker.Lock()
+	defer l.tab.locker.Unlock()
+	if n.Data == "textarea" {
+		for n.FirstChild != nil {
+			n.RemoveChild(n.FirstChild)
+		}
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: value})
+	} else {
+		setAttr(n, "value", value)
+	}
+	l.tab.focused = n
+	return nil
+}
+
+func (l *Locator) Clear(options ...playwright.LocatorClearOptions) error {
+	return l.Fill("")
+}
+
+// Press 在元素上按键，触发 Site.OnPress 注册的行为
+func (l *Locator) Press(key string, options ...playwright.LocatorPressOptions) error {
+	n, err := l.resolveOne()
+	if err != nil {
+		return err
+	}
+	return l.tab.dispatch("press:"+key, n)
+}
+
+// PressSequentially 将文本追加到输入框的值之后
+func (l *Locator) PressSequentially(text string, options ...playwright.LocatorPressSequentiallyOptions) error {
+	value, err := l.InputValue()
+	if err != nil {
+		return err
+	}
+	return l.Fill(value + text)
+}
+
+func (l *Locator) Type(text string, options ...playwright.LocatorTypeOptions) error {
+	return l.PressSequentially(text)
+}
+
+// SelectOption 按值、文本或序号选中下拉框的选项，触发 Site.OnChange 注册的行为
+func (l *Locator) SelectOption(values playwright.SelectOptionValues, options ...playwright.LocatorSelectOptionOptions) ([]string, error) {
+	n, err := l.resolveOne()
+	if err != nil {
+		return nil, err
+	}
+	if n.Data != "select" {
+		return nil, fmt.Errorf("定位器 %s 不是 <select> 元素", l)
+	}
+
+	s, _ := parseSelector("option")
+	l.tab.locker.Lock()
+	var selected []string
+	for i, option := range s.queryAll(n) {
+		value, ok := lookupAttr(option, "value")
+		label := innerText(option)
+		if !ok {
+			value = label
+		}
+		match := values.ValuesOrLabels != nil && (slices.Contains(*values.ValuesOrLabels, value) || slices.Contains(*values.ValuesOrLabels, label)) ||
+			values.Values != nil && slices.Contains(*values.Values, value) ||
+			values.Labels != nil && slices.Contains(*values.Labels, label) ||
+			values.Indexes != nil && slices.Contains(*values.Indexes, i)
+		option.Attr = slices.DeleteFunc(option.Attr, func(a html.Attribute) bool { return a.Key == "selected" })
+		if match {
+			option.Attr = append(option.Attr, html.Attribute{Key: "selected"})
+			selected = append(selected, value)
+		}
+	}
+	l.tab.locker.Unlock()
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: 下拉框 %s 中没有匹配的选项", playwright.ErrTimeout, l)
+	}
+	return selected, l.tab.dispatch("change", n)
+}
+
+func (l *Locator) Page() (playwright.Page, error) {
+	return l.tab.page, nil
+}
@@ -0,0 +1,99 @@
+package fake
+
+import (
+	"github.com/playwright-community/playwright-go"
+)
+
+// Page 实现采集脚本用到的 playwright.Page 方法，其余方法见 unimplemented.go
+type Page struct {
+	tab *TabPage
+}
+
+func (p *Page) Locator(selector string, options ...playwright.PageLocatorOptions) playwright.Locator {
+	return &Locator{tab: p.tab, selector: selector, nth: allNodes}
+}
+
+func (p *Page) Keyboard() playwright.Keyboard {
+	return &Keyboard{tab: p.tab}
+}
+
+func (p *Page) URL() string {
+	return p.tab.URL()
+}
+
+func (p *Page) Title() (string, error) {
+	if err := p.tab.checkOpen(); err != nil {
+		return "", err
+	}
+	return p.tab.Title(), nil
+}
+
+func (p *Page) Content() (string, error) {
+	if err := p.tab.checkOpen(); err != nil {
+		return "", err
+	}
+	return p.tab.HTML(), nil
+}
+
+func (p *Page) SetContent(content string, options ...playwright.PageSetContentOptions) error {
+	if err := p.tab.checkOpen(); err != nil {
+		return err
+	}
+	return p.tab.setContent(p.tab.URL(), content)
+}
+
+// Goto 没有真实的网络响应，返回的 Response 始终为 nil
+func (p *Page) Goto(url string, options ...playwright.PageGotoOptions) (playwright.Response, error) {
+	return nil, p.tab.Goto(url)
+}
+
+// Reload 重新加载夹具，SetContent 等行为对页面的修改会被丢弃
+func (p *Page) Reload(options ...playwright.PageReloadOptions) (playwright.Response, error) {
+	if err := p.tab.checkOpen(); err != nil {
+		return nil, err
+	}
+	return nil, p.tab.load(p.tab.URL())
+}
+
+func (p *Page) Evaluate(expression string, arg ...any) (any, error) {
+	return p.tab.Evaluate(expression, arg...)
+}
+
+func (p *Page) WaitForLoadState(options ...playwright.PageWaitForLoadStateOptions) error {
+	return p.tab.checkOpen()
+}
+
+func (p *Page) WaitForTimeout(timeout float64) {
+}
+
+func (p *Page) Screenshot(options ...playwright.PageScreenshotOptions) ([]byte, error) {
+	return nil, unsupported("Page.Screenshot")
+}
+
+func (p *Page) BringToFront() error {
+	return p.tab.checkOpen()
+}
+
+func (p *Page) IsClosed() bool {
+	return p.tab.IsClosed()
+}
+
+func (p *Page) Close(options ...playwright.PageCloseOptions) error {
+	p.tab.close()
+	return nil
+}
+
+// Keyboard 按键作用于最近一次点击或输入的元素，触发 Site.OnPress 注册的行为
+type Keyboard struct {
+	tab *TabPage
+}
+
+func (k *Keyboard) Press(key string, options ...playwright.KeyboardPressOptions) error {
+	if err := k.tab.checkOpen(); err != nil {
+		return err
+	}
+	k.tab.locker.Lock()
+	target := k.tab.focused
+	k.tab.locker.Unlock()
+	return k.tab.dispatch("press:"+key, target)
+}
@@ -0,0 +1,145 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Action 脚本化的页面行为，在点击、按键等事件发生时执行
+type Action func(tab *TabPage) error
+
+// EvaluateFunc 模拟 Evaluate 执行某段脚本的结果
+type EvaluateFunc func(tab *TabPage, args ...any) (any, error)
+
+type handler struct {
+	url      string // 页面地址，为空时匹配所有页面
+	event    string // click、press:<key>、change
+	selector string
+	action   Action
+}
+
+// Site 假浏览器访问的站点：URL 对应的 HTML 夹具，以及点击、按键、脚本执行的预设行为
+type Site struct {
+	pages      map[string]string
+	handlers   []handler
+	evaluators map[string]EvaluateFunc
+	locker     sync.Mutex
+}
+
+func NewSite() *Site {
+	return &Site{
+		pages:      make(map[string]string),
+		evaluators: make(map[string]EvaluateFunc),
+	}
+}
+
+// Page 注册一个页面，url 可以带 #hash，查找时先精确匹配，再去掉 hash 匹配
+func (s *Site) Page(url string, content string) *Site {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.pages[url] = content
+	return s
+}
+
+// OnClick 点击 url 页面中匹配 selector 的元素（或其后代）时执行 action
+func (s *Site) OnClick(url string, selector string, action Action) *Site {
+	return s.on(url, "click", selector, action)
+}
+
+// OnPress 在 url 页面中匹配 selector 的元素上按下 key 时执行 action，selector 为空时匹配所有元素
+func (s *Site) OnPress(url string, selector string, key string, action Action) *Site {
+	return s.on(url, "press:"+key, selector, action)
+}
+
+// OnChange 修改 url 页面中匹配 selector 的下拉框选项时执行 action
+func (s *Site) OnChange(url string, selector string, action Action) *Site {
+	return s.on(url, "change", selector, action)
+}
+
+// OnEvaluate 设置 Evaluate 执行 expression 时的返回值，expression 按去掉首尾空白后的文本匹配
+func (s *Site) OnEvaluate(expression string, fn EvaluateFunc) *Site {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.evaluators[strings.TrimSpace(expression)] = fn
+	return s
+}
+
+func (s *Site) on(url string, event string, selector string, action Action) *Site {
+	if selector != "" {
+		if _, err := parseSelector(selector); err != nil {
+			panic(fmt.Sprintf("fake: %v", err))
+		}
+	}
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	s.handlers = append(s.handlers, handler{url: url, event: event, selector: selector, action: action})
+	return s
+}
+
+func (s *Site) lookupPage(url string) (string, bool) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if url == "" || url == "about:blank" {
+		return "<html><head></head><body></body></html>", true
+	}
+	if content, ok := s.pages[url]; ok {
+		return content, true
+	}
+	base, _, _ := strings.Cut(url, "#")
+	content, ok := s.pages[base]
+	return content, ok
+}
+
+func (s *Site) lookupHandlers(url string, event string) []handler {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	base, _, _ := strings.Cut(url, "#")
+	var result []handler
+	for _, h := range s.handlers {
+		if h.event == event && (h.url == "" || h.url == url || h.url == base) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+func (s *Site) lookupEvaluator(expression string) (EvaluateFunc, bool) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	fn, ok := s.evaluators[strings.TrimSpace(expression)]
+	return fn, ok
+}
+
+// Navigate 在当前标签页中打开 url
+func Navigate(url string) Action {
+	return func(tab *TabPage) error {
+		return tab.load(url)
+	}
+}
+
+// OpenPopup 打开一个新标签页显示 url，可由 OpenInNewTab 捕获
+func OpenPopup(url string) Action {
+	return func(tab *TabPage) error {
+		return tab.browser.openPopup(url)
+	}
+}
+
+// SetContent 将当前标签页替换为指定 HTML，地址不变，用于模拟 Ajax 刷新列表
+func SetContent(content string) Action {
+	return func(tab *TabPage) error {
+		return tab.setContent(tab.url, content)
+	}
+}
+
+// Sequence 依次执行多个行为
+func Sequence(actions ...Action) Action {
+	return func(tab *TabPage) error {
+		for _, action := range actions {
+			if err := action(tab); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
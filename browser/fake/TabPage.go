@@ -0,0 +1,389 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"rpa-yjj-api/browser"
+
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/net/html"
+)
+
+// TabPage 基于 HTML 夹具的假标签页，实现 browser.TabPage
+type TabPage struct {
+	id      string
+	url     string
+	doc     *html.Node
+	focused *html.Node // 最近一次点击或输入的元素，Keyboard().Press 作用于该元素
+	closed  bool
+	browser *Browser
+	page    *Page
+	locker  sync.Mutex
+}
+
+func newTabPage(id string, b *Browser) *TabPage {
+	t := &TabPage{id: id, browser: b}
+	t.page = &Page{tab: t}
+	return t
+}
+
+func (t *TabPage) ID() string {
+	return t.id
+}
+
+func (t *TabPage) Title() string {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.doc == nil {
+		return ""
+	}
+	return documentTitle(t.doc)
+}
+
+func (t *TabPage) URL() string {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	return t.url
+}
+
+func (t *TabPage) Domain() string {
+	u, err := url.Parse(t.URL())
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(u.Hostname(), ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2] + "." + parts[len(parts)-1]
+}
+
+func (t *TabPage) IsClosed() bool {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	return t.closed
+}
+
+func (t *TabPage) BringToFront() {
+}
+
+func (t *TabPage) Page() playwright.Page {
+	return t.page
+}
+
+func (t *TabPage) V2() browser.TabPageV2 {
+	return &TabPageV2{t}
+}
+
+// HTML 返回当前页面的 HTML，便于测试断言
+func (t *TabPage) HTML() string {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if t.doc == nil {
+		return ""
+	}
+	return outerHTML(t.doc)
+}
+
+func (t *TabPage) checkOpen() error {
+	if t.IsClosed() {
+		return fmt.Errorf("%w: %s", browser.ErrTabClosed, t.id)
+	}
+	return nil
+}
+
+// load 从站点夹具中打开 url
+func (t *TabPage) load(pageURL string) error {
+	if pageURL == "" {
+		pageURL = "about:blank"
+	}
+	content, ok := t.browser.site.lookupPage(pageURL)
+	if !ok {
+		return fmt.Errorf("无法访问网站: 站点中不存在页面 %s", pageURL)
+	}
+	return t.setContent(pageURL, content)
+}
+
+func (t *TabPage) setContent(pageURL string, content string) error {
+	doc, err := parseHTML(content)
+	if err != nil {
+		return fmt.Errorf("解析页面 %s 失败: %v", pageURL, err)
+	}
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.url = pageURL
+	t.doc = doc
+	t.focused = nil
+	return nil
+}
+
+// query 在当前文档中查找匹配选择器的元素
+func (t *TabPage) query(root *html.Node, selectorText string) ([]*html.Node, error) {
+	s, err := parseSelector(selectorText)
+	if err != nil {
+		return nil, err
+	}
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if root == nil {
+		root = t.doc
+	}
+	if root == nil {
+		return nil, nil
+	}
+	return s.queryAll(root), nil
+}
+
+// dispatch 触发元素上的事件，与 DOM 事件冒泡一致，元素的祖先上注册的行为也会执行
+func (t *TabPage) dispatch(event string, target *html.Node) error {
+	t.locker.Lock()
+	t.focused = target
+	currentURL, doc := t.url, t.doc
+	t.locker.Unlock()
+
+	var actions []Action
+	for _, h := range t.browser.site.lookupHandlers(currentURL, event) {
+		if h.selector == "" {
+			actions = append(actions, h.action)
+			continue
+		}
+		s, _ := parseSelector(h.selector)
+		t.locker.Lock()
+		matched := s.queryAll(doc)
+		t.locker.Unlock()
+		if containsAncestor(matched, target) {
+			actions = append(actions, h.action)
+		}
+	}
+	for _, action := range actions {
+		if err := action(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsAncestor(nodes []*html.Node, target *html.Node) bool {
+	for n := target; n != nil; n = n.Parent {
+		for _, node := range nodes {
+			if node == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *TabPage) OpenInNewTab(id string, action func() error, timeout float64) browser.TabPage {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+
+	tabPage, err := t.openInNewTab(ctx, id, action)
+	if err != nil {
+		log.Printf("打开新标签页失败: %v", err)
+		return nil
+	}
+	return tabPage
+}
+
+func (t *TabPage) openInNewTab(ctx context.Context, id string, action func() error) (*TabPage, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", browser.ErrTimeout, err)
+	}
+
+	t.browser.openLocker.Lock()
+	defer t.browser.openLocker.Unlock()
+
+	before := t.browser.popupCount()
+	if err := action(); err != nil {
+		return nil, fmt.Errorf("触发新标签页失败: %w", err)
+	}
+	tabPage := t.browser.claimPopup(id, before)
+	if tabPage == nil {
+		return nil, fmt.Errorf("等待新标签页 %s 失败: %w", id, browser.ErrTimeout)
+	}
+	log.Printf("成功捕获新标签页, ID: %s", id)
+	return tabPage, nil
+}
+
+func (t *TabPage) WaitSelector(selector string, timeout float64) playwright.Locator {
+	locator, err := t.waitSelector(context.Background(), selector)
+	if errors.Is(err, browser.ErrAmbiguous) {
+		log.Printf("选择器匹配到多个元素: %s, 取第一条返回", selector)
+		return t.page.Locator(selector).First()
+	}
+	if err != nil {
+		log.Printf("等待选择器失败: %v", err)
+		return nil
+	}
+	return locator
+}
+
+// waitSelector 夹具是静态的，等待不会让元素出现，未匹配或不可见时直接视为超时
+func (t *TabPage) waitSelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", browser.ErrTimeout, err)
+	}
+	nodes, err := t.query(nil, selector)
+	if err != nil {
+		return nil, err
+	}
+	visible := 0
+	for _, n := range nodes {
+		if !isHidden(n) {
+			visible++
+		}
+	}
+	if visible == 0 {
+		return nil, fmt.Errorf("等待选择器 %s 失败: %w", selector, browser.ErrTimeout)
+	}
+	if len(nodes) > 1 {
+		return nil, fmt.Errorf("%w: %s 共 %d 个", browser.ErrAmbiguous, selector, len(nodes))
+	}
+	return t.page.Locator(selector), nil
+}
+
+func (t *TabPage) QuerySelector(selector string) playwright.Locator {
+	locator, err := t.querySelector(context.Background(), selector)
+	if errors.Is(err, browser.ErrAmbiguous) {
+		log.Printf("选择器匹配到多个元素: %s, 取第一条返回", selector)
+		return t.page.Locator(selector).First()
+	}
+	if err != nil {
+		log.Printf("查询选择器失败: %v", err)
+		return nil
+	}
+	return locator
+}
+
+func (t *TabPage) querySelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	nodes, err := t.query(nil, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: %s", browser.ErrNotFound, selector)
+	}
+	if len(nodes) > 1 {
+		return nil, fmt.Errorf("%w: %s 共 %d 个", browser.ErrAmbiguous, selector, len(nodes))
+	}
+	return t.page.Locator(selector), nil
+}
+
+func (t *TabPage) QuerySelectorAll(selector string) []playwright.Locator {
+	items, err := t.querySelectorAll(context.Background(), selector)
+	if err != nil {
+		log.Printf("查询选择器失败: %v", err)
+		return []playwright.Locator{}
+	}
+	return items
+}
+
+func (t *TabPage) querySelectorAll(ctx context.Context, selector string) ([]playwright.Locator, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	items, err := t.page.Locator(selector).All()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: %s", browser.ErrNotFound, selector)
+	}
+	return items, nil
+}
+
+func (t *TabPage) ClearLocalData() error {
+	return t.checkOpen()
+}
+
+func (t *TabPage) Goto(url string) error {
+	return t.gotoURL(context.Background(), url)
+}
+
+func (t *TabPage) gotoURL(ctx context.Context, url string) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", browser.ErrTimeout, err)
+	}
+	return t.load(url)
+}
+
+func (t *TabPage) Evaluate(expression string, arg ...any) (any, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, err
+	}
+	fn, ok := t.browser.site.lookupEvaluator(expression)
+	if !ok {
+		return nil, fmt.Errorf("fake: 未设置脚本的执行结果: %s", strings.TrimSpace(expression))
+	}
+	return fn(t, arg...)
+}
+
+func (t *TabPage) close() {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.closed = true
+}
+
+// TabPageV2 是 TabPage 的 browser.TabPageV2 视图
+type TabPageV2 struct {
+	*TabPage
+}
+
+func (t *TabPageV2) V1() browser.TabPage {
+	return t.TabPage
+}
+
+func (t *TabPageV2) OpenInNewTab(ctx context.Context, id string, action func() error) (browser.TabPageV2, error) {
+	tabPage, err := t.openInNewTab(ctx, id, action)
+	if err != nil {
+		return nil, err
+	}
+	return tabPage.V2(), nil
+}
+
+func (t *TabPageV2) WaitSelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	return t.waitSelector(ctx, selector)
+}
+
+func (t *TabPageV2) QuerySelector(ctx context.Context, selector string) (playwright.Locator, error) {
+	return t.querySelector(ctx, selector)
+}
+
+func (t *TabPageV2) QuerySelectorAll(ctx context.Context, selector string) ([]playwright.Locator, error) {
+	return t.querySelectorAll(ctx, selector)
+}
+
+func (t *TabPageV2) ClearLocalData(ctx context.Context) error {
+	return t.TabPage.ClearLocalData()
+}
+
+func (t *TabPageV2) Goto(ctx context.Context, url string) error {
+	return t.gotoURL(ctx, url)
+}
+
+func (t *TabPageV2) Evaluate(ctx context.Context, expression string, arg ...any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", browser.ErrTimeout, err)
+	}
+	return t.TabPage.Evaluate(expression, arg...)
+}
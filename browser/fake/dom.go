@@ -0,0 +1,201 @@
+package fake
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 会在 InnerText 中产生换行的块级元素
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "tbody": true,
+	"thead": true, "tfoot": true, "tr": true, "ul": true,
+}
+
+var spaces = regexp.MustCompile(`[ \t\r\f\v]+`)
+
+func parseHTML(content string) (*html.Node, error) {
+	return html.Parse(strings.NewReader(content))
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, name string) string {
+	value, _ := lookupAttr(n, name)
+	return value
+}
+
+func setAttr(n *html.Node, name string, value string) {
+	for i, a := range n.Attr {
+		if a.Key == name {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: name, Val: value})
+}
+
+// elementIndex 返回元素在兄弟元素中的序号，从 1 开始
+func elementIndex(n *html.Node) int {
+	index := 1
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			index++
+		}
+	}
+	return index
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// textContent 所有后代文本节点的拼接，与 DOM 的 textContent 一致
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// 文本匹配时跳过的元素，与 Playwright 一致
+var textlessElements = map[string]bool{"head": true, "noscript": true, "script": true, "style": true}
+
+func hasText(n *html.Node) bool {
+	return n.Type == html.ElementNode && !textlessElements[n.Data]
+}
+
+// elementText 文本选择器匹配的文本：textContent 去掉 <script>、<style> 等元素的内容
+func elementText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+			case c.Type == html.ElementNode && textlessElements[c.Data]:
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// normalizeText 合并连续的空白并去掉首尾空白
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// innerText 近似浏览器的 innerText：合并空白，块级元素和 <br> 换行，单元格之间用制表符分隔
+func innerText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(spaces.ReplaceAllString(strings.ReplaceAll(n.Data, "\n", " "), " "))
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "head", "template":
+				return
+			case "br":
+				b.WriteString("\n")
+				return
+			}
+		}
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			b.WriteString("\n")
+		}
+		if n.Type == html.ElementNode && (n.Data == "td" || n.Data == "th") && nextElement(n) != nil {
+			b.WriteString("\t")
+		}
+	}
+	walk(n)
+
+	lines := strings.Split(b.String(), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Trim(line, " ")
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+func innerHTML(n *html.Node) string {
+	var buf bytes.Buffer
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&buf, c)
+	}
+	return buf.String()
+}
+
+func outerHTML(n *html.Node) string {
+	var buf bytes.Buffer
+	html.Render(&buf, n)
+	return buf.String()
+}
+
+// documentTitle 返回 <title> 的文本
+func documentTitle(doc *html.Node) string {
+	s, _ := parseSelector("title")
+	nodes := s.queryAll(doc)
+	if len(nodes) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(textContent(nodes[0]))
+}
+
+// isHidden 近似的可见性判断：元素或祖先带有 hidden 属性或 display:none 样式
+func isHidden(n *html.Node) bool {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode {
+			continue
+		}
+		if _, ok := lookupAttr(p, "hidden"); ok {
+			return true
+		}
+		style := strings.ReplaceAll(attr(p, "style"), " ", "")
+		if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package fake
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const domDoc = `<html><head><title>
+	药品查询 </title><style>p { color: red }</style></head><body>
+<div id="detail">
+	<h3 id="h">注册信息</h3>
+	<table id="t"><tbody>
+		<tr><td>注册证号</td><td>H20200001</td></tr>
+		<tr><td>产品名称</td><td>阿司匹林<br>肠溶片</td></tr>
+	</tbody></table>
+	<p id="p">  多个   空白
+	合并 <script>var x = 1;</script></p>
+	<ul id="l"><li>一</li><li id="second">二</li><li id="last">三</li></ul>
+	<span id="s1" hidden>隐藏</span>
+	<div style="display: none"><span id="s2">隐藏</span></div>
+	<div style="visibility:hidden"><span id="s3">隐藏</span></div>
+	<span id="s4" style="color: red">显示</span>
+</div>
+</body></html>`
+
+func findByID(t *testing.T, doc *html.Node, id string) *html.Node {
+	t.Helper()
+	s, _ := parseSelector("#" + id)
+	nodes := s.queryAll(doc)
+	if len(nodes) != 1 {
+		t.Fatalf("#%s 匹配 %d 个元素", id, len(nodes))
+	}
+	return nodes[0]
+}
+
+func TestDOMText(t *testing.T) {
+	doc, err := parseHTML(domDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := documentTitle(doc); got != "药品查询" {
+		t.Errorf("documentTitle() = %q", got)
+	}
+
+	table := findByID(t, doc, "t")
+	if got, want := innerText(table), "注册证号\tH20200001\n产品名称\t阿司匹林\n肠溶片"; got != want {
+		t.Errorf("innerText(table) = %q, want %q", got, want)
+	}
+	list := findByID(t, doc, "l")
+	if got, want := innerText(list), "一\n二\n三"; got != want {
+		t.Errorf("innerText(ul) = %q, want %q", got, want)
+	}
+
+	p := findByID(t, doc, "p")
+	if got, want := innerText(p), "多个 空白 合并"; got != want {
+		t.Errorf("innerText(p) = %q, want %q", got, want)
+	}
+	if got, want := textContent(p), "  多个   空白\n\t合并 var x = 1;"; got != want {
+		t.Errorf("textContent(p) = %q, want %q", got, want)
+	}
+	if got, want := normalizeText(elementText(p)), "多个 空白 合并"; got != want {
+		t.Errorf("elementText(p) = %q, want %q", got, want)
+	}
+	if got := innerHTML(findByID(t, doc, "h")); got != "注册信息" {
+		t.Errorf("innerHTML(h3) = %q", got)
+	}
+	if got := outerHTML(findByID(t, doc, "second")); got != `<li id="second">二</li>` {
+		t.Errorf("outerHTML(li) = %q", got)
+	}
+}
+
+func TestDOMStructure(t *testing.T) {
+	doc, err := parseHTML(domDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := findByID(t, doc, "second")
+	if got := elementIndex(second); got != 2 {
+		t.Errorf("elementIndex(#second) = %d, want 2", got)
+	}
+	if got := nextElement(second); got == nil || attr(got, "id") != "last" {
+		t.Errorf("nextElement(#second) = %v, want #last", got)
+	}
+	if got := nextElement(findByID(t, doc, "last")); got != nil {
+		t.Errorf("nextElement(#last) = %v, want nil", got)
+	}
+
+	if _, ok := lookupAttr(second, "class"); ok {
+		t.Error("lookupAttr(#second, class) 不应存在")
+	}
+	setAttr(second, "class", "a")
+	setAttr(second, "class", "b")
+	if value, ok := lookupAttr(second, "class"); !ok || value != "b" || len(second.Attr) != 2 {
+		t.Errorf("setAttr 后属性为 %v", second.Attr)
+	}
+
+	for id, want := range map[string]bool{"s1": true, "s2": true, "s3": true, "s4": false, "h": false} {
+		if got := isHidden(findByID(t, doc, id)); got != want {
+			t.Errorf("isHidden(#%s) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// harFile HAR 1.2 中用到的字段
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// LoadHAR 把 HAR 文件中成功的 GET HTML 响应注册为站点的页面，同一 URL 以最后一条为准。
+// 与 browser.HarReplay 一样只能打开 HAR 中有的页面；假浏览器不执行脚本，HAR 中应保存渲染后的 HTML，
+// 点击、按键等行为仍需用 OnClick 等脚本化
+func (s *Site) LoadHAR(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("无法读取 HAR 文件: %w", err)
+	}
+	var har harFile
+	if err = json.Unmarshal(data, &har); err != nil {
+		return fmt.Errorf("无法解析 HAR 文件 %s: %w", path, err)
+	}
+	loaded := 0
+	for _, entry := range har.Log.Entries {
+		content := entry.Response.Content
+		if entry.Request.Method != "GET" || entry.Response.Status != 200 || !strings.Contains(content.MimeType, "html") {
+			continue
+		}
+		text := content.Text
+		if content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return fmt.Errorf("HAR 中 %s 的响应体无法解码: %w", entry.Request.URL, err)
+			}
+			text = string(decoded)
+		}
+		s.Page(entry.Request.URL, text)
+		loaded++
+	}
+	if loaded == 0 {
+		return fmt.Errorf("HAR 文件 %s 中没有 HTML 页面", path)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package fake
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHAR = `{"log": {"version": "1.2", "entries": [
+	{"request": {"method": "GET", "url": "https://example.com/list.html"},
+	 "response": {"status": 200, "content": {"mimeType": "text/html; charset=utf-8", "text": "<html><body><p id=\"v\">1</p></body></html>"}}},
+	{"request": {"method": "GET", "url": "https://example.com/list.html"},
+	 "response": {"status": 200, "content": {"mimeType": "text/html", "text": "<html><body><p id=\"v\">2</p></body></html>"}}},
+	{"request": {"method": "GET", "url": "https://example.com/detail.html?id=1"},
+	 "response": {"status": 200, "content": {"mimeType": "text/html", "encoding": "base64", "text": "PGh0bWw+PGJvZHk+PHAgaWQ9InYiPmRldGFpbDwvcD48L2JvZHk+PC9odG1sPg=="}}},
+	{"request": {"method": "GET", "url": "https://example.com/missing.html"},
+	 "response": {"status": 404, "content": {"mimeType": "text/html", "text": "not found"}}},
+	{"request": {"method": "POST", "url": "https://example.com/api/list"},
+	 "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{}"}}}
+]}}`
+
+func writeHAR(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "site.har")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHAR(t *testing.T) {
+	site := NewSite()
+	if err := site.LoadHAR(writeHAR(t, testHAR)); err != nil {
+		t.Fatalf("LoadHAR() error = %v", err)
+	}
+	b := NewBrowser(site)
+	defer b.Close()
+
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"https://example.com/list.html", "2", false}, // 同一 URL 以最后一条为准
+		{"https://example.com/list.html#page=3", "2", false},
+		{"https://example.com/detail.html?id=1", "detail", false},
+		{"https://example.com/missing.html", "", true}, // 失败的响应不回放
+		{"https://example.com/api/list", "", true},     // 只回放 HTML 页面
+		{"https://example.com/other.html", "", true},   // 与回放模式一样，HAR 中没有的请求失败
+	}
+	tab := b.FindTabPage("default")
+	for _, tt := range tests {
+		err := tab.Goto(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Goto(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got, err := tab.Page().Locator("#v").InnerText(); err != nil || got != tt.want {
+			t.Errorf("Goto(%s) 后 #v = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadHARErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"不是 JSON", "not json", "无法解析"},
+		{"没有 HTML 页面", `{"log": {"entries": []}}`, "没有 HTML 页面"},
+		{"响应体无法解码", `{"log": {"entries": [{"request": {"method": "GET", "url": "https://example.com/"},
+			"response": {"status": 200, "content": {"mimeType": "text/html", "encoding": "base64", "text": "%%%"}}}]}}`, "无法解码"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSite().LoadHAR(writeHAR(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadHAR() error = %v, want 包含 %q", err, tt.want)
+			}
+		})
+	}
+	if err := NewSite().LoadHAR(filepath.Join(t.TempDir(), "missing.har")); err == nil {
+		t.Error("文件不存在时 LoadHAR 应返回错误")
+	}
+}
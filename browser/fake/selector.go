@@ -0,0 +1,445 @@
+package fake
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 只支持采集脚本中用到的 CSS 子集：
+// 标签、#id、.class、[attr]、[attr=value]、:nth-child(n)、:first-child、:last-child，
+// 以及后代（空格）、子元素（>）组合符和逗号分隔的选择器列表；
+// 另外支持 Playwright 的 text=、:has-text()、:text-is() 和 :has()，其余伪类和选择器引擎解析时报错
+
+type attrCond struct {
+	name  string
+	value string
+	exact bool // 是否要求值相等，否则只要求属性存在
+}
+
+// textCond 按元素文本匹配，文本的空白先合并
+type textCond struct {
+	match   func(text string) bool
+	deepest bool // 与 text= 和 :text-is() 一致，只匹配子元素都不满足条件的最内层元素
+}
+
+type compound struct {
+	tag       string
+	id        string
+	classes   []string
+	attrs     []attrCond
+	texts     []textCond
+	has       []complexSelector // :has() 中的相对选择器
+	nthChild  int               // 0 表示不限制
+	lastChild bool
+}
+
+type complexSelector struct {
+	anchor      byte // 相对选择器开头的组合符，:has(> label) 中为 '>'，其余为 ' '
+	parts       []compound
+	combinators []byte // combinators[i] 连接 parts[i] 和 parts[i+1]，取值 ' ' 或 '>'
+}
+
+type selector []complexSelector
+
+func parseSelector(text string) (selector, error) {
+	if value, ok := strings.CutPrefix(text, "text="); ok {
+		cond, err := parseTextEngine(value)
+		if err != nil {
+			return nil, err
+		}
+		return selector{{parts: []compound{{texts: []textCond{cond}}}}}, nil
+	}
+	if strings.Contains(text, ">>") {
+		return nil, fmt.Errorf("不支持 >> 连接的选择器: %s", text)
+	}
+	var list selector
+	for _, item := range splitTopLevel(text, ',') {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, fmt.Errorf("无效的选择器: %s", text)
+		}
+		cs, err := parseComplex(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, cs)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("空选择器")
+	}
+	return list, nil
+}
+
+// splitTopLevel 按分隔符拆分，忽略括号和引号中的分隔符
+func splitTopLevel(text string, sep byte) []string {
+	var parts []string
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+func parseComplex(text string) (complexSelector, error) {
+	var cs complexSelector
+	i := 0
+	pending := byte(0)
+	for i < len(text) {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			if len(cs.parts) > 0 && pending == 0 {
+				pending = ' '
+			}
+			i++
+			continue
+		}
+		if c == '>' {
+			if len(cs.parts) == 0 {
+				return cs, fmt.Errorf("选择器不能以 > 开头: %s", text)
+			}
+			pending = '>'
+			i++
+			continue
+		}
+		part, next, err := parseCompound(text, i)
+		if err != nil {
+			return cs, err
+		}
+		if next == i {
+			return cs, fmt.Errorf("无效的选择器: %s", text)
+		}
+		if len(cs.parts) > 0 {
+			if pending == 0 {
+				return cs, fmt.Errorf("无效的选择器: %s", text)
+			}
+			cs.combinators = append(cs.combinators, pending)
+		}
+		cs.parts = append(cs.parts, part)
+		pending = 0
+		i = next
+	}
+	if len(cs.parts) == 0 || pending == '>' {
+		return cs, fmt.Errorf("无效的选择器: %s", text)
+	}
+	return cs, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func readName(text string, i int) (string, int) {
+	start := i
+	for i < len(text) && isNameChar(text[i]) {
+		i++
+	}
+	return text[start:i], i
+}
+
+func parseCompound(text string, i int) (compound, int, error) {
+	var part compound
+	if text[i] == '*' {
+		i++
+	} else if isNameChar(text[i]) {
+		part.tag, i = readName(text, i)
+		part.tag = strings.ToLower(part.tag)
+	}
+	for i < len(text) {
+		switch text[i] {
+		case '#':
+			part.id, i = readName(text, i+1)
+		case '.':
+			var class string
+			class, i = readName(text, i+1)
+			part.classes = append(part.classes, class)
+		case '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return part, i, fmt.Errorf("缺少 ]: %s", text)
+			}
+			body := text[i+1 : i+end]
+			i += end + 1
+			name, value, exact := strings.Cut(body, "=")
+			value = strings.Trim(strings.TrimSpace(value), `'"`)
+			part.attrs = append(part.attrs, attrCond{name: strings.TrimSpace(name), value: value, exact: exact})
+		case ':':
+			var pseudo string
+			pseudo, i = readName(text, i+1)
+			switch pseudo {
+			case "first-child":
+				part.nthChild = 1
+			case "last-child":
+				part.lastChild = true
+			case "nth-child", "has-text", "text-is", "has":
+				var arg string
+				var err error
+				arg, i, err = readArgument(text, i, pseudo)
+				if err != nil {
+					return part, i, err
+				}
+				switch pseudo {
+				case "nth-child":
+					n, err := strconv.Atoi(arg)
+					if err != nil || n < 1 {
+						return part, i, fmt.Errorf("不支持的 nth-child 参数: %s", text)
+					}
+					part.nthChild = n
+				case "has-text":
+					part.texts = append(part.texts, containsText(unquote(arg), false))
+				case "text-is":
+					expected := normalizeText(unquote(arg))
+					part.texts = append(part.texts, textCond{
+						match:   func(text string) bool { return text == expected },
+						deepest: true,
+					})
+				case "has":
+					cs, err := parseRelative(arg)
+					if err != nil {
+						return part, i, err
+					}
+					part.has = append(part.has, cs)
+				}
+			default:
+				return part, i, fmt.Errorf("不支持的伪类 :%s", pseudo)
+			}
+		default:
+			return part, i, nil
+		}
+	}
+	return part, i, nil
+}
+
+// readArgument 读取伪类括号中的参数，括号可以嵌套，引号中的括号不计
+func readArgument(text string, i int, pseudo string) (string, int, error) {
+	if i >= len(text) || text[i] != '(' {
+		return "", i, fmt.Errorf(":%s 缺少参数: %s", pseudo, text)
+	}
+	depth, quote := 0, byte(0)
+	for j := i; j < len(text); j++ {
+		c := text[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(text[i+1 : j]), j + 1, nil
+			}
+		}
+	}
+	return "", i, fmt.Errorf("缺少 ): %s", text)
+}
+
+// unquote 去掉参数两端成对的引号
+func unquote(text string) string {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+// parseRelative 解析 :has() 中的相对选择器，可以用 > 开头
+func parseRelative(text string) (complexSelector, error) {
+	anchor := byte(' ')
+	if rest, ok := strings.CutPrefix(text, ">"); ok {
+		anchor, text = '>', strings.TrimSpace(rest)
+	}
+	if len(splitTopLevel(text, ',')) > 1 {
+		return complexSelector{}, fmt.Errorf(":has() 中不支持选择器列表: %s", text)
+	}
+	cs, err := parseComplex(text)
+	cs.anchor = anchor
+	return cs, err
+}
+
+// containsText 忽略大小写的子串匹配，与 Playwright 不带引号的 text= 和 :has-text() 一致
+func containsText(value string, deepest bool) textCond {
+	expected := strings.ToLower(normalizeText(value))
+	return textCond{
+		match:   func(text string) bool { return strings.Contains(strings.ToLower(text), expected) },
+		deepest: deepest,
+	}
+}
+
+// parseTextEngine 解析 text= 之后的部分：带引号时要求文本完全相同（区分大小写），
+// /pattern/ 或 /pattern/i 按正则表达式匹配，否则忽略大小写匹配子串
+func parseTextEngine(value string) (textCond, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return textCond{}, fmt.Errorf("text= 缺少文本")
+	}
+	if quoted := unquote(value); quoted != value {
+		expected := normalizeText(quoted)
+		return textCond{match: func(text string) bool { return text == expected }, deepest: true}, nil
+	}
+	if strings.HasPrefix(value, "/") {
+		end := strings.LastIndexByte(value, '/')
+		if end == 0 {
+			return textCond{}, fmt.Errorf("text= 的正则表达式缺少结尾的 /: %s", value)
+		}
+		pattern, flags := value[1:end], value[end+1:]
+		switch flags {
+		case "":
+		case "i":
+			pattern = "(?i)" + pattern
+		default:
+			return textCond{}, fmt.Errorf("text= 的正则表达式不支持标志 %s", flags)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return textCond{}, fmt.Errorf("text= 的正则表达式无效: %v", err)
+		}
+		return textCond{match: re.MatchString, deepest: true}, nil
+	}
+	return containsText(value, true), nil
+}
+
+// matches 判断元素的文本是否满足条件，不匹配 <script>、<style> 等不显示文本的元素
+func (cond *textCond) matches(n *html.Node) bool {
+	if !hasText(n) || !cond.match(normalizeText(elementText(n))) {
+		return false
+	}
+	if cond.deepest {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && hasText(c) && cond.match(normalizeText(elementText(c))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (part *compound) match(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if part.tag != "" && n.Data != part.tag {
+		return false
+	}
+	if part.id != "" && attr(n, "id") != part.id {
+		return false
+	}
+	if len(part.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, class := range part.classes {
+			found := false
+			for _, c := range classes {
+				if c == class {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, cond := range part.attrs {
+		value, ok := lookupAttr(n, cond.name)
+		if !ok || cond.exact && value != cond.value {
+			return false
+		}
+	}
+	if part.nthChild > 0 && elementIndex(n) != part.nthChild {
+		return false
+	}
+	if part.lastChild && nextElement(n) != nil {
+		return false
+	}
+	for i := range part.texts {
+		if !part.texts[i].matches(n) {
+			return false
+		}
+	}
+	for i := range part.has {
+		if !part.has[i].matchWithin(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAt 判断节点是否匹配 parts[:idx+1]，从右向左匹配；
+// scope 不为空时是 :has() 的相对选择器，只在 scope 的后代中匹配，anchor 为 '>' 时最左侧的部分必须是 scope 的子元素
+func (cs *complexSelector) matchAt(n *html.Node, idx int, scope *html.Node) bool {
+	if !cs.parts[idx].match(n) {
+		return false
+	}
+	if idx == 0 {
+		return scope == nil || cs.anchor != '>' || n.Parent == scope
+	}
+	switch cs.combinators[idx-1] {
+	case '>':
+		parent := n.Parent
+		return parent != nil && parent != scope && cs.matchAt(parent, idx-1, scope)
+	default:
+		for p := n.Parent; p != nil && p != scope; p = p.Parent {
+			if cs.matchAt(p, idx-1, scope) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchWithin 判断 scope 的后代中是否有元素匹配相对选择器
+func (cs *complexSelector) matchWithin(scope *html.Node) bool {
+	var walk func(n *html.Node) bool
+	walk = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && cs.matchAt(c, len(cs.parts)-1, scope) || walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(scope)
+}
+
+// queryAll 返回 root 的后代中匹配选择器的元素，按文档顺序排列；
+// 与 querySelectorAll 一致，选择器左侧部分可以匹配 root 之外的祖先
+func (s selector) queryAll(root *html.Node) []*html.Node {
+	var result []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode {
+				for i := range s {
+					cs := &s[i]
+					if cs.matchAt(c, len(cs.parts)-1, nil) {
+						result = append(result, c)
+						break
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return result
+}
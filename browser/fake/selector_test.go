@@ -0,0 +1,153 @@
+package fake
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const selectorDoc = `<html><head><title>t</title><script id="s">var label = "注册证号";</script></head><body>
+<div id="list" class="list main">
+	<table id="t"><tbody>
+		<tr id="r1"><td id="c11">H001</td><td id="c12"><button id="b1" class="el-button el-button--primary">详情</button></td></tr>
+		<tr id="r2"><td id="c21">H002</td><td id="c22"><button id="b2" class="el-button">详情</button></td></tr>
+		<tr id="r3"><td id="c31">H003</td><td id="c32"><span id="p3">暂无</span></td></tr>
+	</tbody></table>
+	<a id="next" data-page="2" href="#">下一页</a>
+</div>
+<form id="search">
+	<div id="f1" class="el-form-item"><label id="l1">注册证号</label><div id="w1"><input id="i1"></div></div>
+	<div id="f2" class="el-form-item"><label id="l2">产品名称（中文）</label><div id="w2"><input id="i2"></div></div>
+	<div id="f3" class="el-form-item"><div id="w3"><label id="l3">注册证号 </label></div><input id="i3"></div>
+	<button id="submit"><span id="submit-text">高级  搜索</span></button>
+	<button id="reset">重置</button>
+</form>
+</body></html>`
+
+func queryIDs(t *testing.T, root *html.Node, text string) string {
+	t.Helper()
+	s, err := parseSelector(text)
+	if err != nil {
+		t.Fatalf("parseSelector(%q) error = %v", text, err)
+	}
+	var ids []string
+	for _, n := range s.queryAll(root) {
+		ids = append(ids, attr(n, "id"))
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSelectorQuery(t *testing.T) {
+	doc, err := parseHTML(selectorDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"tr", "r1,r2,r3"},
+		{"#list", "list"},
+		{"div.list.main", "list"},
+		{".list.other", ""},
+		{"*#r2", "r2"},
+		{"a[data-page]", "next"},
+		{"a[data-page='2']", "next"},
+		{`a[data-page="3"]`, ""},
+		{"tr:first-child", "r1"},
+		{"tr:last-child", "r3"},
+		{"tr:nth-child(2) > td:nth-child(1)", "c21"},
+		{"table > tbody > tr td button", "b1,b2"},
+		{"table > button", ""},
+		{"#b2, #b1", "b1,b2"}, // 选择器列表按文档顺序返回
+		{"button.el-button--primary", "b1"},
+		// Playwright 的文本选择器
+		{"text=详情", "b1,b2"},
+		{"text=  暂无 ", "p3"},
+		{"text=h002", "c21"}, // 不带引号时忽略大小写
+		{`text="H00"`, ""},   // 带引号时要求文本完全相同
+		{`text='H003'`, "c31"},
+		{"text=高级 搜索", "submit-text"}, // 合并空白后匹配，只返回最内层的元素
+		{"text=/高级\\s*(搜索|检索)/", "submit-text"},
+		{"text=/^h00[12]$/i", "c11,c21"},
+		{"text=注册证号", "l1,l3"}, // 不匹配 <script> 中的文本
+		{"button:has-text('搜索')", "submit"},
+		{"form :has-text('注册证号')", "f1,l1,f3,w3,l3"}, // :has-text() 也匹配祖先元素
+		{"label:text-is('注册证号')", "l1,l3"},
+		{"label:text-is('注册')", ""},
+		{"td:text-is(\"H001\")", "c11"},
+		{"tr:has(button)", "r1,r2"},
+		{"tr:has(> td > button.el-button--primary)", "r1"},
+		{"tr:has(> button)", ""},
+		{".el-form-item:has(label:has-text('产品名称')) input", "i2"},
+		{"#search div:has(> label:has-text('注册证号')) input", "i1"}, // #w3 的子元素中有标签，但输入框不在 #w3 中
+		{"div:has(> label:text-is('注册证号')) > div > input", "i1"},
+		{"form div:has(> div > label) input", "i3"},
+		{"tr:has(td:text-is('H002')) button", "b2"},
+	}
+	for _, tt := range tests {
+		if got := queryIDs(t, doc, tt.selector); got != tt.want {
+			t.Errorf("%s 匹配 %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+}
+
+// 选择器左侧可以匹配 root 之外的祖先，:has() 只在元素内查找
+func TestSelectorQueryFromRoot(t *testing.T) {
+	doc, err := parseHTML(selectorDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := parseSelector("#r2")
+	row := s.queryAll(doc)[0]
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"button", "b2"},
+		{"table button", "b2"},
+		{"td:nth-child(1)", "c21"},
+		{"tr", ""},
+		{"text=详情", "b2"},
+		{"td:has(button)", "c22"},
+		{"td:has(span)", ""},
+	}
+	for _, tt := range tests {
+		if got := queryIDs(t, row, tt.selector); got != tt.want {
+			t.Errorf("在 #r2 中 %s 匹配 %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"a,",
+		"> a",
+		"a >",
+		"a b >",
+		"a!b",
+		"a[href",
+		"tr:nth-child",
+		"tr:nth-child(0)",
+		"tr:nth-child(2n+1)",
+		"tr:nth-child(2",
+		"a:hover",
+		"a:text('x')",
+		"a:visible",
+		"div:has(a, b)",
+		"div:has(> )",
+		"text=",
+		"text=/a",
+		"text=/a/g",
+		"text=/(/",
+		"#list >> button",
+		"role=button",
+	}
+	for _, text := range tests {
+		if _, err := parseSelector(text); err == nil {
+			t.Errorf("parseSelector(%q) 应返回错误", text)
+		}
+	}
+}
@@ -0,0 +1,636 @@
+package fake
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 以下是 Page、Locator 和 Keyboard 中采集脚本用不到的方法，全部显式实现，调用时不会 panic：
+// 有 error 返回值的方法返回 ErrUnsupported，返回 Locator 的方法返回所有操作都报 ErrUnsupported 的定位器，
+// 其余方法返回零值或什么都不做
+
+// ErrUnsupported 假浏览器没有实现的方法返回的错误
+var ErrUnsupported = errors.New("fake: 不支持的方法")
+
+func unsupported(method string) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, method)
+}
+
+func (p *Page) unsupportedLocator(method string) playwright.Locator {
+	return &Locator{tab: p.tab, nth: allNodes, err: unsupported(method)}
+}
+
+func (l *Locator) unsupportedLocator(method string) playwright.Locator {
+	return &Locator{tab: l.tab, nth: allNodes, err: unsupported(method)}
+}
+
+var _ playwright.Page = (*Page)(nil)
+
+func (p *Page) AddInitScript(script playwright.Script) error {
+	return unsupported("Page.AddInitScript")
+}
+
+func (p *Page) AddLocatorHandler(locator playwright.Locator, handler func(playwright.Locator), options ...playwright.PageAddLocatorHandlerOptions) error {
+	return unsupported("Page.AddLocatorHandler")
+}
+
+func (p *Page) AddScriptTag(options playwright.PageAddScriptTagOptions) (playwright.ElementHandle, error) {
+	return nil, unsupported("Page.AddScriptTag")
+}
+
+func (p *Page) AddStyleTag(options playwright.PageAddStyleTagOptions) (playwright.ElementHandle, error) {
+	return nil, unsupported("Page.AddStyleTag")
+}
+
+func (p *Page) Check(selector string, options ...playwright.PageCheckOptions) error {
+	return unsupported("Page.Check")
+}
+
+func (p *Page) Click(selector string, options ...playwright.PageClickOptions) error {
+	return unsupported("Page.Click")
+}
+
+func (p *Page) Clock() playwright.Clock {
+	return nil
+}
+
+func (p *Page) Context() playwright.BrowserContext {
+	return nil
+}
+
+func (p *Page) Dblclick(selector string, options ...playwright.PageDblclickOptions) error {
+	return unsupported("Page.Dblclick")
+}
+
+func (p *Page) DispatchEvent(selector string, typ string, eventInit any, options ...playwright.PageDispatchEventOptions) error {
+	return unsupported("Page.DispatchEvent")
+}
+
+func (p *Page) DragAndDrop(source string, target string, options ...playwright.PageDragAndDropOptions) error {
+	return unsupported("Page.DragAndDrop")
+}
+
+func (p *Page) Emit(name string, payload ...any) bool {
+	return false
+}
+
+func (p *Page) EmulateMedia(options ...playwright.PageEmulateMediaOptions) error {
+	return unsupported("Page.EmulateMedia")
+}
+
+func (p *Page) EvalOnSelector(selector string, expression string, arg any, options ...playwright.PageEvalOnSelectorOptions) (any, error) {
+	return nil, unsupported("Page.EvalOnSelector")
+}
+
+func (p *Page) EvalOnSelectorAll(selector string, expression string, arg ...any) (any, error) {
+	return nil, unsupported("Page.EvalOnSelectorAll")
+}
+
+func (p *Page) EvaluateHandle(expression string, arg ...any) (playwright.JSHandle, error) {
+	return nil, unsupported("Page.EvaluateHandle")
+}
+
+func (p *Page) ExpectConsoleMessage(cb func() error, options ...playwright.PageExpectConsoleMessageOptions) (playwright.ConsoleMessage, error) {
+	return nil, unsupported("Page.ExpectConsoleMessage")
+}
+
+func (p *Page) ExpectDownload(cb func() error, options ...playwright.PageExpectDownloadOptions) (playwright.Download, error) {
+	return nil, unsupported("Page.ExpectDownload")
+}
+
+func (p *Page) ExpectEvent(event string, cb func() error, options ...playwright.PageExpectEventOptions) (any, error) {
+	return nil, unsupported("Page.ExpectEvent")
+}
+
+func (p *Page) ExpectFileChooser(cb func() error, options ...playwright.PageExpectFileChooserOptions) (playwright.FileChooser, error) {
+	return nil, unsupported("Page.ExpectFileChooser")
+}
+
+func (p *Page) ExpectNavigation(cb func() error, options ...playwright.PageExpectNavigationOptions) (playwright.Response, error) {
+	return nil, unsupported("Page.ExpectNavigation")
+}
+
+func (p *Page) ExpectPopup(cb func() error, options ...playwright.PageExpectPopupOptions) (playwright.Page, error) {
+	return nil, unsupported("Page.ExpectPopup")
+}
+
+func (p *Page) ExpectRequest(urlOrPredicate any, cb func() error, options ...playwright.PageExpectRequestOptions) (playwright.Request, error) {
+	return nil, unsupported("Page.ExpectRequest")
+}
+
+func (p *Page) ExpectRequestFinished(cb func() error, options ...playwright.PageExpectRequestFinishedOptions) (playwright.Request, error) {
+	return nil, unsupported("Page.ExpectRequestFinished")
+}
+
+func (p *Page) ExpectResponse(urlOrPredicate any, cb func() error, options ...playwright.PageExpectResponseOptions) (playwright.Response, error) {
+	return nil, unsupported("Page.ExpectResponse")
+}
+
+func (p *Page) ExpectWebSocket(cb func() error, options ...playwright.PageExpectWebSocketOptions) (playwright.WebSocket, error) {
+	return nil, unsupported("Page.ExpectWebSocket")
+}
+
+func (p *Page) ExpectWorker(cb func() error, options ...playwright.PageExpectWorkerOptions) (playwright.Worker, error) {
+	return nil, unsupported("Page.ExpectWorker")
+}
+
+func (p *Page) ExposeBinding(name string, binding playwright.BindingCallFunction, handle ...bool) error {
+	return unsupported("Page.ExposeBinding")
+}
+
+func (p *Page) ExposeFunction(name string, binding playwright.ExposedFunction) error {
+	return unsupported("Page.ExposeFunction")
+}
+
+func (p *Page) Fill(selector string, value string, options ...playwright.PageFillOptions) error {
+	return unsupported("Page.Fill")
+}
+
+func (p *Page) Focus(selector string, options ...playwright.PageFocusOptions) error {
+	return unsupported("Page.Focus")
+}
+
+func (p *Page) Frame(options ...playwright.PageFrameOptions) playwright.Frame {
+	return nil
+}
+
+func (p *Page) FrameLocator(selector string) playwright.FrameLocator {
+	return nil
+}
+
+func (p *Page) Frames() []playwright.Frame {
+	return nil
+}
+
+func (p *Page) GetAttribute(selector string, name string, options ...playwright.PageGetAttributeOptions) (string, error) {
+	return "", unsupported("Page.GetAttribute")
+}
+
+func (p *Page) GetByAltText(text any, options ...playwright.PageGetByAltTextOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByAltText")
+}
+
+func (p *Page) GetByLabel(text any, options ...playwright.PageGetByLabelOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByLabel")
+}
+
+func (p *Page) GetByPlaceholder(text any, options ...playwright.PageGetByPlaceholderOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByPlaceholder")
+}
+
+func (p *Page) GetByRole(role playwright.AriaRole, options ...playwright.PageGetByRoleOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByRole")
+}
+
+func (p *Page) GetByTestId(testId any) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByTestId")
+}
+
+func (p *Page) GetByText(text any, options ...playwright.PageGetByTextOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByText")
+}
+
+func (p *Page) GetByTitle(text any, options ...playwright.PageGetByTitleOptions) playwright.Locator {
+	return p.unsupportedLocator("Page.GetByTitle")
+}
+
+func (p *Page) GoBack(options ...playwright.PageGoBackOptions) (playwright.Response, error) {
+	return nil, unsupported("Page.GoBack")
+}
+
+func (p *Page) GoForward(options ...playwright.PageGoForwardOptions) (playwright.Response, error) {
+	return nil, unsupported("Page.GoForward")
+}
+
+func (p *Page) Hover(selector string, options ...playwright.PageHoverOptions) error {
+	return unsupported("Page.Hover")
+}
+
+func (p *Page) InnerHTML(selector string, options ...playwright.PageInnerHTMLOptions) (string, error) {
+	return "", unsupported("Page.InnerHTML")
+}
+
+func (p *Page) InnerText(selector string, options ...playwright.PageInnerTextOptions) (string, error) {
+	return "", unsupported("Page.InnerText")
+}
+
+func (p *Page) InputValue(selector string, options ...playwright.PageInputValueOptions) (string, error) {
+	return "", unsupported("Page.InputValue")
+}
+
+func (p *Page) IsChecked(selector string, options ...playwright.PageIsCheckedOptions) (bool, error) {
+	return false, unsupported("Page.IsChecked")
+}
+
+func (p *Page) IsDisabled(selector string, options ...playwright.PageIsDisabledOptions) (bool, error) {
+	return false, unsupported("Page.IsDisabled")
+}
+
+func (p *Page) IsEditable(selector string, options ...playwright.PageIsEditableOptions) (bool, error) {
+	return false, unsupported("Page.IsEditable")
+}
+
+func (p *Page) IsEnabled(selector string, options ...playwright.PageIsEnabledOptions) (bool, error) {
+	return false, unsupported("Page.IsEnabled")
+}
+
+func (p *Page) IsHidden(selector string, options ...playwright.PageIsHiddenOptions) (bool, error) {
+	return false, unsupported("Page.IsHidden")
+}
+
+func (p *Page) IsVisible(selector string, options ...playwright.PageIsVisibleOptions) (bool, error) {
+	return false, unsupported("Page.IsVisible")
+}
+
+func (p *Page) ListenerCount(name string) int {
+	return 0
+}
+
+func (p *Page) MainFrame() playwright.Frame {
+	return nil
+}
+
+func (p *Page) Mouse() playwright.Mouse {
+	return nil
+}
+
+func (p *Page) On(name string, handler any) {
+}
+
+func (p *Page) OnClose(fn func(playwright.Page)) {
+}
+
+func (p *Page) OnConsole(fn func(playwright.ConsoleMessage)) {
+}
+
+func (p *Page) OnCrash(fn func(playwright.Page)) {
+}
+
+func (p *Page) OnDOMContentLoaded(fn func(playwright.Page)) {
+}
+
+func (p *Page) OnDialog(fn func(playwright.Dialog)) {
+}
+
+func (p *Page) OnDownload(fn func(playwright.Download)) {
+}
+
+func (p *Page) OnFileChooser(fn func(playwright.FileChooser)) {
+}
+
+func (p *Page) OnFrameAttached(fn func(playwright.Frame)) {
+}
+
+func (p *Page) OnFrameDetached(fn func(playwright.Frame)) {
+}
+
+func (p *Page) OnFrameNavigated(fn func(playwright.Frame)) {
+}
+
+func (p *Page) OnLoad(fn func(playwright.Page)) {
+}
+
+func (p *Page) OnPageError(fn func(error)) {
+}
+
+func (p *Page) OnPopup(fn func(playwright.Page)) {
+}
+
+func (p *Page) OnRequest(fn func(playwright.Request)) {
+}
+
+func (p *Page) OnRequestFailed(fn func(playwright.Request)) {
+}
+
+func (p *Page) OnRequestFinished(fn func(playwright.Request)) {
+}
+
+func (p *Page) OnResponse(fn func(playwright.Response)) {
+}
+
+func (p *Page) OnWebSocket(fn func(playwright.WebSocket)) {
+}
+
+func (p *Page) OnWorker(fn func(playwright.Worker)) {
+}
+
+func (p *Page) Once(name string, handler any) {
+}
+
+func (p *Page) Opener() (playwright.Page, error) {
+	return nil, unsupported("Page.Opener")
+}
+
+func (p *Page) PDF(options ...playwright.PagePdfOptions) ([]byte, error) {
+	return nil, unsupported("Page.PDF")
+}
+
+func (p *Page) Pause() error {
+	return unsupported("Page.Pause")
+}
+
+func (p *Page) Press(selector string, key string, options ...playwright.PagePressOptions) error {
+	return unsupported("Page.Press")
+}
+
+func (p *Page) QuerySelector(selector string, options ...playwright.PageQuerySelectorOptions) (playwright.ElementHandle, error) {
+	return nil, unsupported("Page.QuerySelector")
+}
+
+func (p *Page) QuerySelectorAll(selector string) ([]playwright.ElementHandle, error) {
+	return nil, unsupported("Page.QuerySelectorAll")
+}
+
+func (p *Page) RemoveListener(name string, handler any) {
+}
+
+func (p *Page) RemoveListeners(name string) {
+}
+
+func (p *Page) RemoveLocatorHandler(locator playwright.Locator) error {
+	return unsupported("Page.RemoveLocatorHandler")
+}
+
+func (p *Page) Request() playwright.APIRequestContext {
+	return nil
+}
+
+func (p *Page) RequestGC() error {
+	return unsupported("Page.RequestGC")
+}
+
+func (p *Page) Route(url any, handler func(playwright.Route), times ...int) error {
+	return unsupported("Page.Route")
+}
+
+func (p *Page) RouteFromHAR(har string, options ...playwright.PageRouteFromHAROptions) error {
+	return unsupported("Page.RouteFromHAR")
+}
+
+func (p *Page) RouteWebSocket(url any, handler func(playwright.WebSocketRoute)) error {
+	return unsupported("Page.RouteWebSocket")
+}
+
+func (p *Page) SelectOption(selector string, values playwright.SelectOptionValues, options ...playwright.PageSelectOptionOptions) ([]string, error) {
+	return nil, unsupported("Page.SelectOption")
+}
+
+func (p *Page) SetChecked(selector string, checked bool, options ...playwright.PageSetCheckedOptions) error {
+	return unsupported("Page.SetChecked")
+}
+
+func (p *Page) SetDefaultNavigationTimeout(timeout float64) {
+}
+
+func (p *Page) SetDefaultTimeout(timeout float64) {
+}
+
+func (p *Page) SetExtraHTTPHeaders(headers map[string]string) error {
+	return unsupported("Page.SetExtraHTTPHeaders")
+}
+
+func (p *Page) SetInputFiles(selector string, files any, options ...playwright.PageSetInputFilesOptions) error {
+	return unsupported("Page.SetInputFiles")
+}
+
+func (p *Page) SetViewportSize(width int, height int) error {
+	return unsupported("Page.SetViewportSize")
+}
+
+func (p *Page) Tap(selector string, options ...playwright.PageTapOptions) error {
+	return unsupported("Page.Tap")
+}
+
+func (p *Page) TextContent(selector string, options ...playwright.PageTextContentOptions) (string, error) {
+	return "", unsupported("Page.TextContent")
+}
+
+func (p *Page) Touchscreen() playwright.Touchscreen {
+	return nil
+}
+
+func (p *Page) Type(selector string, text string, options ...playwright.PageTypeOptions) error {
+	return unsupported("Page.Type")
+}
+
+func (p *Page) Uncheck(selector string, options ...playwright.PageUncheckOptions) error {
+	return unsupported("Page.Uncheck")
+}
+
+func (p *Page) Unroute(url any, handler ...func(playwright.Route)) error {
+	return unsupported("Page.Unroute")
+}
+
+func (p *Page) UnrouteAll(options ...playwright.PageUnrouteAllOptions) error {
+	return unsupported("Page.UnrouteAll")
+}
+
+func (p *Page) Video() playwright.Video {
+	return nil
+}
+
+func (p *Page) ViewportSize() *playwright.Size {
+	return nil
+}
+
+func (p *Page) WaitForEvent(event string, options ...playwright.PageWaitForEventOptions) (any, error) {
+	return nil, unsupported("Page.WaitForEvent")
+}
+
+func (p *Page) WaitForFunction(expression string, arg any, options ...playwright.PageWaitForFunctionOptions) (playwright.JSHandle, error) {
+	return nil, unsupported("Page.WaitForFunction")
+}
+
+func (p *Page) WaitForSelector(selector string, options ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error) {
+	return nil, unsupported("Page.WaitForSelector")
+}
+
+func (p *Page) WaitForURL(url any, options ...playwright.PageWaitForURLOptions) error {
+	return unsupported("Page.WaitForURL")
+}
+
+func (p *Page) Workers() []playwright.Worker {
+	return nil
+}
+
+var _ playwright.Locator = (*Locator)(nil)
+
+func (l *Locator) AllTextContents() ([]string, error) {
+	return nil, unsupported("Locator.AllTextContents")
+}
+
+func (l *Locator) And(locator playwright.Locator) playwright.Locator {
+	return l.unsupportedLocator("Locator.And")
+}
+
+func (l *Locator) AriaSnapshot(options ...playwright.LocatorAriaSnapshotOptions) (string, error) {
+	return "", unsupported("Locator.AriaSnapshot")
+}
+
+func (l *Locator) Blur(options ...playwright.LocatorBlurOptions) error {
+	return unsupported("Locator.Blur")
+}
+
+func (l *Locator) BoundingBox(options ...playwright.LocatorBoundingBoxOptions) (*playwright.Rect, error) {
+	return nil, unsupported("Locator.BoundingBox")
+}
+
+func (l *Locator) Check(options ...playwright.LocatorCheckOptions) error {
+	return unsupported("Locator.Check")
+}
+
+func (l *Locator) ContentFrame() playwright.FrameLocator {
+	return nil
+}
+
+func (l *Locator) Dblclick(options ...playwright.LocatorDblclickOptions) error {
+	return unsupported("Locator.Dblclick")
+}
+
+func (l *Locator) DispatchEvent(typ string, eventInit any, options ...playwright.LocatorDispatchEventOptions) error {
+	return unsupported("Locator.DispatchEvent")
+}
+
+func (l *Locator) DragTo(target playwright.Locator, options ...playwright.LocatorDragToOptions) error {
+	return unsupported("Locator.DragTo")
+}
+
+func (l *Locator) ElementHandle(options ...playwright.LocatorElementHandleOptions) (playwright.ElementHandle, error) {
+	return nil, unsupported("Locator.ElementHandle")
+}
+
+func (l *Locator) ElementHandles() ([]playwright.ElementHandle, error) {
+	return nil, unsupported("Locator.ElementHandles")
+}
+
+func (l *Locator) Err() error {
+	return unsupported("Locator.Err")
+}
+
+func (l *Locator) Evaluate(expression string, arg any, options ...playwright.LocatorEvaluateOptions) (any, error) {
+	return nil, unsupported("Locator.Evaluate")
+}
+
+func (l *Locator) EvaluateAll(expression string, arg ...any) (any, error) {
+	return nil, unsupported("Locator.EvaluateAll")
+}
+
+func (l *Locator) EvaluateHandle(expression string, arg any, options ...playwright.LocatorEvaluateHandleOptions) (playwright.JSHandle, error) {
+	return nil, unsupported("Locator.EvaluateHandle")
+}
+
+func (l *Locator) Filter(options ...playwright.LocatorFilterOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.Filter")
+}
+
+func (l *Locator) Focus(options ...playwright.LocatorFocusOptions) error {
+	return unsupported("Locator.Focus")
+}
+
+func (l *Locator) FrameLocator(selector string) playwright.FrameLocator {
+	return nil
+}
+
+func (l *Locator) GetByAltText(text any, options ...playwright.LocatorGetByAltTextOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByAltText")
+}
+
+func (l *Locator) GetByLabel(text any, options ...playwright.LocatorGetByLabelOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByLabel")
+}
+
+func (l *Locator) GetByPlaceholder(text any, options ...playwright.LocatorGetByPlaceholderOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByPlaceholder")
+}
+
+func (l *Locator) GetByRole(role playwright.AriaRole, options ...playwright.LocatorGetByRoleOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByRole")
+}
+
+func (l *Locator) GetByTestId(testId any) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByTestId")
+}
+
+func (l *Locator) GetByText(text any, options ...playwright.LocatorGetByTextOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByText")
+}
+
+func (l *Locator) GetByTitle(text any, options ...playwright.LocatorGetByTitleOptions) playwright.Locator {
+	return l.unsupportedLocator("Locator.GetByTitle")
+}
+
+func (l *Locator) Highlight() error {
+	return unsupported("Locator.Highlight")
+}
+
+func (l *Locator) Hover(options ...playwright.LocatorHoverOptions) error {
+	return unsupported("Locator.Hover")
+}
+
+func (l *Locator) IsChecked(options ...playwright.LocatorIsCheckedOptions) (bool, error) {
+	return false, unsupported("Locator.IsChecked")
+}
+
+func (l *Locator) IsDisabled(options ...playwright.LocatorIsDisabledOptions) (bool, error) {
+	return false, unsupported("Locator.IsDisabled")
+}
+
+func (l *Locator) IsEditable(options ...playwright.LocatorIsEditableOptions) (bool, error) {
+	return false, unsupported("Locator.IsEditable")
+}
+
+func (l *Locator) IsEnabled(options ...playwright.LocatorIsEnabledOptions) (bool, error) {
+	return false, unsupported("Locator.IsEnabled")
+}
+
+func (l *Locator) Or(locator playwright.Locator) playwright.Locator {
+	return l.unsupportedLocator("Locator.Or")
+}
+
+func (l *Locator) Screenshot(options ...playwright.LocatorScreenshotOptions) ([]byte, error) {
+	return nil, unsupported("Locator.Screenshot")
+}
+
+func (l *Locator) ScrollIntoViewIfNeeded(options ...playwright.LocatorScrollIntoViewIfNeededOptions) error {
+	return unsupported("Locator.ScrollIntoViewIfNeeded")
+}
+
+func (l *Locator) SelectText(options ...playwright.LocatorSelectTextOptions) error {
+	return unsupported("Locator.SelectText")
+}
+
+func (l *Locator) SetChecked(checked bool, options ...playwright.LocatorSetCheckedOptions) error {
+	return unsupported("Locator.SetChecked")
+}
+
+func (l *Locator) SetInputFiles(files any, options ...playwright.LocatorSetInputFilesOptions) error {
+	return unsupported("Locator.SetInputFiles")
+}
+
+func (l *Locator) Tap(options ...playwright.LocatorTapOptions) error {
+	return unsupported("Locator.Tap")
+}
+
+func (l *Locator) Uncheck(options ...playwright.LocatorUncheckOptions) error {
+	return unsupported("Locator.Uncheck")
+}
+
+var _ playwright.Keyboard = (*Keyboard)(nil)
+
+func (k *Keyboard) Down(key string) error {
+	return unsupported("Keyboard.Down")
+}
+
+func (k *Keyboard) InsertText(text string) error {
+	return unsupported("Keyboard.InsertText")
+}
+
+func (k *Keyboard) Type(text string, options ...playwright.KeyboardTypeOptions) error {
+	return unsupported("Keyboard.Type")
+}
+
+func (k *Keyboard) Up(key string) error {
+	return unsupported("Keyboard.Up")
+}
@@ -0,0 +1,55 @@
+package fake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 未实现的方法返回 ErrUnsupported，不会因为嵌入的 nil 接口 panic
+func TestUnsupportedMethods(t *testing.T) {
+	site := NewSite().Page("https://example.com/", `<html><body><button id="b">确定</button></body></html>`)
+	b := NewBrowser(site)
+	defer b.Close()
+	tab := b.FindTabPage("default")
+	if err := tab.Goto("https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	page := tab.Page()
+
+	if _, err := page.Screenshot(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Page.Screenshot() error = %v", err)
+	}
+	if err := page.WaitForURL("**"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Page.WaitForURL() error = %v", err)
+	}
+	if err := page.Keyboard().Type("a"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Keyboard.Type() error = %v", err)
+	}
+	if err := page.GetByRole(*playwright.AriaRoleButton).Click(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Page.GetByRole().Click() error = %v", err)
+	}
+
+	button := page.Locator("#b")
+	if _, err := button.BoundingBox(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Locator.BoundingBox() error = %v", err)
+	}
+	if err := button.Hover(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Locator.Hover() error = %v", err)
+	}
+	// 返回 Locator 的方法得到的定位器，后续操作同样报错
+	for name, locator := range map[string]playwright.Locator{
+		"Filter":           button.Filter(),
+		"GetByText":        button.GetByText("确定"),
+		"Locator(Locator)": button.Locator(page.Locator("body")),
+		"Filter().First()": button.Filter().First(),
+	} {
+		if _, err := locator.Count(); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s.Count() error = %v", name, err)
+		}
+	}
+	if text, err := button.InnerText(); err != nil || text != "确定" {
+		t.Errorf("已实现的方法不受影响: %q, %v", text, err)
+	}
+}
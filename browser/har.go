@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// HarMode 网络流量的录制/回放方式
+type HarMode int
+
+const (
+	HarOff    HarMode = iota // 正常访问网络
+	HarRecord                // 访问网络并把所有流量录制到 HAR 文件，浏览器关闭时写入磁盘
+	HarReplay                // 只从 HAR 文件回放，HAR 中不存在的请求直接中止，不访问网络
+)
+
+func (m HarMode) String() string {
+	switch m {
+	case HarOff:
+		return "off"
+	case HarRecord:
+		return "record"
+	case HarReplay:
+		return "replay"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// ParseHarMode 将命令行参数转换为 HarMode
+func ParseHarMode(mode string) (HarMode, error) {
+	switch mode {
+	case "", "off":
+		return HarOff, nil
+	case "record":
+		return HarRecord, nil
+	case "replay":
+		return HarReplay, nil
+	default:
+		return HarOff, fmt.Errorf("不支持的 HAR 模式: %s", mode)
+	}
+}
+
+// applyHar 按启动参数为浏览器上下文开启 HAR 录制或回放
+func applyHar(browserContext playwright.BrowserContext, options *Options) error {
+	if options.HarMode == HarOff {
+		return nil
+	}
+	if options.HarPath == "" {
+		return fmt.Errorf("HAR 模式 %s 需要指定 HAR 文件路径", options.HarMode)
+	}
+
+	var url any
+	if options.HarURLFilter != "" {
+		url = options.HarURLFilter
+	}
+
+	switch options.HarMode {
+	case HarRecord:
+		if err := os.MkdirAll(filepath.Dir(options.HarPath), os.ModePerm); err != nil {
+			return fmt.Errorf("无法创建 HAR 目录: %v", err)
+		}
+		err := browserContext.RouteFromHAR(options.HarPath, playwright.BrowserContextRouteFromHAROptions{
+			Update:        playwright.Bool(true),
+			UpdateContent: playwright.RouteFromHarUpdateContentPolicyEmbed,
+			UpdateMode:    playwright.HarModeFull,
+			URL:           url,
+		})
+		if err != nil {
+			return fmt.Errorf("无法开启 HAR 录制: %v", err)
+		}
+		log.Printf("正在录制网络流量到: %s", options.HarPath)
+	case HarReplay:
+		if _, err := os.Stat(options.HarPath); err != nil {
+			return fmt.Errorf("无法读取 HAR 文件: %v", err)
+		}
+		err := browserContext.RouteFromHAR(options.HarPath, playwright.BrowserContextRouteFromHAROptions{
+			NotFound: playwright.HarNotFoundAbort,
+			URL:      url,
+		})
+		if err != nil {
+			return fmt.Errorf("无法开启 HAR 回放: %v", err)
+		}
+		log.Printf("正在从 HAR 回放网络流量: %s", options.HarPath)
+	default:
+		return fmt.Errorf("不支持的 HAR 模式: %s", options.HarMode)
+	}
+	return nil
+}
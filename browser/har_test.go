@@ -0,0 +1,97 @@
+package browser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHarMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    HarMode
+		wantErr bool
+	}{
+		{"", HarOff, false},
+		{"off", HarOff, false},
+		{"record", HarRecord, false},
+		{"replay", HarReplay, false},
+		{"Replay", HarOff, true},
+		{"playback", HarOff, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHarMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHarMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHarMode(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+		if !tt.wantErr && tt.input != "" && got.String() != tt.input {
+			t.Errorf("HarMode(%d).String() = %s, want %s", got, got.String(), tt.input)
+		}
+	}
+}
+
+// 参数检查在访问浏览器上下文之前完成，因此不需要启动浏览器
+func TestApplyHarOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr string
+	}{
+		{"关闭时不处理", Options{HarMode: HarOff}, ""},
+		{"录制缺少路径", Options{HarMode: HarRecord}, "需要指定 HAR 文件路径"},
+		{"回放缺少路径", Options{HarMode: HarReplay}, "需要指定 HAR 文件路径"},
+		{"回放文件不存在", Options{HarMode: HarReplay, HarPath: filepath.Join(t.TempDir(), "missing.har")}, "无法读取 HAR 文件"},
+		{"未知模式", Options{HarMode: HarMode(9), HarPath: "run.har"}, "不支持的 HAR 模式"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyHar(nil, &tt.options)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("applyHar() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("applyHar() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// memorySessionStore 保存在内存中的会话存储
+type memorySessionStore map[string]string
+
+func (m memorySessionStore) Get(key string) string { return m[key] }
+
+func (m memorySessionStore) SetEx(key string, value string, expiredAt int64) { m[key] = value }
+
+func (m memorySessionStore) Remove(keys ...string) int {
+	removed := 0
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			delete(m, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// 回放时不读取浏览器上下文，也不覆盖已保存的会话
+func TestReplaySkipsSessionSave(t *testing.T) {
+	store := memorySessionStore{"session:nmpa.gov.cn": "saved"}
+	b := &EdgeBrowser{
+		harMode: HarReplay,
+		session: &SessionOptions{Store: store, Domains: []string{"nmpa.gov.cn"}},
+	}
+	if err := b.SaveSession(); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if len(store) != 1 || store["session:nmpa.gov.cn"] != "saved" {
+		t.Errorf("回放时会话被修改: %v", store)
+	}
+}
@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -22,6 +23,7 @@ type TabPage interface {
 	Goto(url string) error
 	Evaluate(expression string, arg ...any) (any, error)
 	Page() playwright.Page
+	V2() TabPageV2
 }
 
 type Browser interface {
@@ -29,7 +31,39 @@ type Browser interface {
 	NewTabPage(id string, url string) TabPage
 	FindTabPage(id string) TabPage
 	SwitchToTabPage(id string) error
+	CloseTabPage(id string) error
 	Close() error
+	V2() BrowserV2
+}
+
+// TabPageV2 方法接受 context 控制取消和超时，失败时返回错误而不是 nil，
+// 错误可用 errors.Is 与 ErrNotFound、ErrAmbiguous、ErrTimeout、ErrTabClosed 比较
+type TabPageV2 interface {
+	ID() string
+	Title() string
+	URL() string
+	Domain() string
+	IsClosed() bool
+	BringToFront()
+	OpenInNewTab(ctx context.Context, id string, action func() error) (TabPageV2, error)
+	WaitSelector(ctx context.Context, selector string) (playwright.Locator, error)
+	QuerySelector(ctx context.Context, selector string) (playwright.Locator, error)
+	QuerySelectorAll(ctx context.Context, selector string) ([]playwright.Locator, error)
+	ClearLocalData(ctx context.Context) error
+	Goto(ctx context.Context, url string) error
+	Evaluate(ctx context.Context, expression string, arg ...any) (any, error)
+	Page() playwright.Page
+	V1() TabPage
+}
+
+type BrowserV2 interface {
+	TabPages() []TabPageV2
+	NewTabPage(ctx context.Context, id string, url string) (TabPageV2, error)
+	FindTabPage(id string) (TabPageV2, error)
+	SwitchToTabPage(id string) error
+	CloseTabPage(id string) error
+	Close() error
+	V1() Browser
 }
 
 func ParseJson[T any](jsonstr string) (T, error) {
@@ -50,10 +84,46 @@ func Stringify[T any](obj T) (string, error) {
 	return string(jsonstr), nil
 }
 
-func StartEdgeBrowser(edgePath string, startPort, endPort int) (Browser, error) {
-	browser, err := newEdgeBrowser(edgePath, startPort, endPort)
+// Options 浏览器启动参数
+type Options struct {
+	Mode           LaunchMode      // 启动方式，默认通过 CDP 连接本机 Edge
+	EdgePath       string          // LaunchEdgeCDP: msedge.exe 的路径
+	StartPort      int             // LaunchEdgeCDP: 调试端口搜索起点
+	EndPort        int             // LaunchEdgeCDP: 调试端口搜索终点
+	Headless       bool            // LaunchChromium: 是否无头运行
+	UserDataDir    string          // 专用的用户数据目录；LaunchEdgeCDP 为空时使用临时目录，LaunchChromium 为空时使用临时上下文
+	Channel        string          // LaunchChromium: 浏览器渠道，如 "msedge"、"chrome"，为空时使用 Playwright 自带的 Chromium
+	ExecutablePath string          // LaunchChromium: 指定浏览器可执行文件路径
+	Args           []string        // LaunchChromium: 额外的启动参数
+	InstallDriver  bool            // 启动前自动安装 Playwright 驱动及所需浏览器
+	HarMode        HarMode         // 网络流量录制/回放方式，默认不录制
+	HarPath        string          // HAR 文件路径，录制时写入，回放时读取
+	HarURLFilter   string          // 只录制/回放 URL 匹配该 glob 的请求，为空时为所有请求
+	Stealth        *StealthProfile // 反检测配置，为 nil 时使用 DefaultStealthProfile，传空配置可关闭
+	Session        *SessionOptions // 会话保存和恢复，为 nil 时每次启动都是全新会话
+}
+
+func StartBrowser(options *Options) (Browser, error) {
+	browser, err := newEdgeBrowser(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start Edge browser: %w", err)
+		return nil, fmt.Errorf("failed to start %s browser: %w", options.Mode, err)
 	}
 	return browser, nil
 }
+
+func StartEdgeBrowser(edgePath string, startPort, endPort int) (Browser, error) {
+	return StartBrowser(&Options{
+		Mode:      LaunchEdgeCDP,
+		EdgePath:  edgePath,
+		StartPort: startPort,
+		EndPort:   endPort,
+	})
+}
+
+func StartChromiumBrowser(headless bool, userDataDir string) (Browser, error) {
+	return StartBrowser(&Options{
+		Mode:        LaunchChromium,
+		Headless:    headless,
+		UserDataDir: userDataDir,
+	})
+}
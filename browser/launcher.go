@@ -0,0 +1,226 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// LaunchMode 浏览器启动方式
+type LaunchMode int
+
+const (
+	LaunchEdgeCDP  LaunchMode = iota // 通过 CDP 连接（或启动）本机的 msedge.exe
+	LaunchChromium                   // 通过 Playwright 自带的 Launch API 启动 Chromium（可无头，支持 Linux）
+)
+
+func (m LaunchMode) String() string {
+	switch m {
+	case LaunchEdgeCDP:
+		return "edge-cdp"
+	case LaunchChromium:
+		return "chromium"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// ParseLaunchMode 将命令行参数转换为 LaunchMode
+func ParseLaunchMode(mode string) (LaunchMode, error) {
+	switch mode {
+	case "", "edge-cdp":
+		return LaunchEdgeCDP, nil
+	case "chromium":
+		return LaunchChromium, nil
+	default:
+		return LaunchEdgeCDP, fmt.Errorf("不支持的启动方式: %s", mode)
+	}
+}
+
+// Launcher 负责启动浏览器并返回可用的浏览器上下文，EdgeBrowser 只依赖该接口
+type Launcher interface {
+	// Launch 启动（或连接）浏览器，返回后续所有标签页共用的上下文
+	Launch(pw *playwright.Playwright) (playwright.BrowserContext, error)
+	// DebugPort 返回远程调试端口，未使用调试端口时返回 0
+	DebugPort() int
+	// Close 关闭由 Launch 打开的浏览器
+	Close() error
+}
+
+func newLauncher(options *Options) (Launcher, error) {
+	switch options.Mode {
+	case LaunchEdgeCDP:
+		return &edgeCDPLauncher{
+			edgePath:    options.EdgePath,
+			startPort:   options.StartPort,
+			endPort:     options.EndPort,
+			userDataDir: options.UserDataDir,
+		}, nil
+	case LaunchChromium:
+		return &chromiumLauncher{
+			headless:       options.Headless,
+			userDataDir:    options.UserDataDir,
+			channel:        options.Channel,
+			executablePath: options.ExecutablePath,
+			args:           options.Args,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的启动方式: %s", options.Mode)
+	}
+}
+
+// edgeCDPLauncher 连接到指定调试端口上的 Edge，找不到时启动一个新的 Edge 实例，
+// 自己启动的实例由 process 管理，关闭时只终止该实例
+type edgeCDPLauncher struct {
+	edgePath    string
+	startPort   int
+	endPort     int
+	userDataDir string
+	port        int
+	browser     playwright.Browser
+	process     *edgeProcess
+}
+
+func (l *edgeCDPLauncher) Launch(pw *playwright.Playwright) (playwright.BrowserContext, error) {
+	// 1. 获取一个可用端口，用于调试
+	debugPort, err := getValidPort(l.startPort, l.endPort)
+	if err != nil {
+		return nil, fmt.Errorf("无法获取可用端口: %v", err)
+	}
+
+	// 2. 尝试连接到已运行的 Edge 实例
+	browser, found := connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
+	if !found {
+		// 如果没有找到已运行的 Edge 实例，则使用专用的用户数据目录启动一个新实例
+		userDataDir, removeDir, err := prepareUserDataDir(l.userDataDir)
+		if err != nil {
+			return nil, err
+		}
+		cmd, err := startNewEdge(l.edgePath, fmt.Sprintf("%d", debugPort), userDataDir)
+		if err != nil {
+			if removeDir {
+				os.RemoveAll(userDataDir)
+			}
+			return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
+		}
+		l.process = newEdgeProcess(cmd, userDataDir, removeDir)
+
+		// 等待片刻，确保浏览器启动
+		time.Sleep(2 * time.Second)
+		log.Printf("Edge 浏览器已通过系统命令启动，调试端口: %d\n", debugPort)
+
+		// 连接到新启动的 Edge 实例
+		browser, found = connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
+		if !found {
+			l.process.Terminate()
+			return nil, fmt.Errorf("无法连接到新启动的 Edge 实例")
+		}
+	} else {
+		log.Printf("已找到正在运行的 Edge 实例，调试端口:%d\n", debugPort)
+	}
+
+	// 3. 获取浏览器上下文
+	contexts := browser.Contexts()
+	if len(contexts) == 0 {
+		browser.Close()
+		if l.process != nil {
+			l.process.Terminate()
+		}
+		return nil, fmt.Errorf("未找到任何浏览器上下文")
+	}
+
+	l.port = debugPort
+	l.browser = browser
+	return contexts[0], nil
+}
+
+func (l *edgeCDPLauncher) DebugPort() int {
+	return l.port
+}
+
+func (l *edgeCDPLauncher) Close() error {
+	var err error
+	if l.browser != nil {
+		err = l.browser.Close()
+	}
+	// 只终止自己启动的 Edge，连接到的已有实例保持运行
+	if l.process != nil {
+		if e := l.process.Terminate(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// chromiumLauncher 使用 Playwright 的 Launch API 启动 Chromium，
+// 指定 userDataDir 时使用持久化上下文，否则使用一次性的新上下文
+type chromiumLauncher struct {
+	headless       bool
+	userDataDir    string
+	channel        string
+	executablePath string
+	args           []string
+	browser        playwright.Browser
+	context        playwright.BrowserContext
+}
+
+func (l *chromiumLauncher) Launch(pw *playwright.Playwright) (playwright.BrowserContext, error) {
+	var channel, executablePath *string
+	if l.channel != "" {
+		channel = playwright.String(l.channel)
+	}
+	if l.executablePath != "" {
+		executablePath = playwright.String(l.executablePath)
+	}
+
+	if l.userDataDir != "" {
+		browserContext, err := pw.Chromium.LaunchPersistentContext(l.userDataDir, playwright.BrowserTypeLaunchPersistentContextOptions{
+			Headless:       playwright.Bool(l.headless),
+			Channel:        channel,
+			ExecutablePath: executablePath,
+			Args:           l.args,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("无法启动 Chromium: %v", err)
+		}
+		log.Printf("Chromium 已启动，headless: %v, 用户数据目录: %s", l.headless, l.userDataDir)
+		l.context = browserContext
+		return browserContext, nil
+	}
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless:       playwright.Bool(l.headless),
+		Channel:        channel,
+		ExecutablePath: executablePath,
+		Args:           l.args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("无法启动 Chromium: %v", err)
+	}
+	browserContext, err := browser.NewContext()
+	if err != nil {
+		browser.Close()
+		return nil, fmt.Errorf("无法创建浏览器上下文: %v", err)
+	}
+	log.Printf("Chromium 已启动，headless: %v", l.headless)
+	l.browser = browser
+	l.context = browserContext
+	return browserContext, nil
+}
+
+func (l *chromiumLauncher) DebugPort() int {
+	return 0
+}
+
+func (l *chromiumLauncher) Close() error {
+	if l.browser != nil {
+		return l.browser.Close()
+	}
+	if l.context != nil {
+		return l.context.Close()
+	}
+	return nil
+}
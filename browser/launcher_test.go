@@ -0,0 +1,30 @@
+package browser
+
+import "testing"
+
+func TestParseLaunchMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LaunchMode
+		wantErr bool
+	}{
+		{"", LaunchEdgeCDP, false},
+		{"edge-cdp", LaunchEdgeCDP, false},
+		{"chromium", LaunchChromium, false},
+		{"Chromium", LaunchEdgeCDP, true},
+		{"firefox", LaunchEdgeCDP, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLaunchMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLaunchMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLaunchMode(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+		if !tt.wantErr && tt.input != "" && got.String() != tt.input {
+			t.Errorf("LaunchMode(%d).String() = %s, want %s", got, got.String(), tt.input)
+		}
+	}
+}
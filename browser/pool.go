@@ -0,0 +1,246 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+)
+
+// ErrPoolClosed 浏览器池已关闭
+var ErrPoolClosed = errors.New("浏览器池已关闭")
+
+// PoolOptions 浏览器池参数
+type PoolOptions struct {
+	Size        int                                     // 最多同时存在的浏览器数量，默认为 2
+	Browser     Options                                 // 每个浏览器的启动参数，UserDataDir 会按序号拆分为子目录
+	NewBrowser  func(options *Options) (Browser, error) // 创建浏览器，为空时使用 StartBrowser
+	HealthCheck func(b Browser) error                   // 借出前检查浏览器是否可用，为空时使用 CheckBrowser
+}
+
+// Pool 浏览器池，每个成员是独立启动的浏览器实例（各自的调试端口、用户数据目录和 Cookies），
+// 用于并发采集多个页码区间；成员在第一次借出时才启动，归还后保留 Cookies 供下次借出复用
+type Pool struct {
+	options PoolOptions
+	idle    []*poolMember
+	created int // 已启动且未关闭的成员数量
+	seq     int // 成员序号，用于区分用户数据目录
+	closed  bool
+	wakeup  chan struct{}
+	locker  sync.Mutex
+}
+
+type poolMember struct {
+	seq     int
+	browser Browser
+}
+
+func NewPool(options *PoolOptions) (*Pool, error) {
+	opts := *options
+	if opts.Size <= 0 {
+		opts.Size = 2
+	}
+	if opts.Size > 1 && opts.Browser.HarMode == HarRecord {
+		return nil, fmt.Errorf("多个浏览器不能同时录制到同一个 HAR 文件")
+	}
+	if opts.NewBrowser == nil {
+		opts.NewBrowser = StartBrowser
+	}
+	if opts.HealthCheck == nil {
+		opts.HealthCheck = CheckBrowser
+	}
+	return &Pool{
+		options: opts,
+		wakeup:  make(chan struct{}),
+	}, nil
+}
+
+// CheckBrowser 默认的健康检查：浏览器至少有一个标签页，且所有标签页都未关闭（浏览器崩溃或断开时标签页会被标记为关闭）
+func CheckBrowser(b Browser) error {
+	tabPages := b.TabPages()
+	if len(tabPages) == 0 {
+		return fmt.Errorf("浏览器没有任何标签页")
+	}
+	for _, tabPage := range tabPages {
+		if tabPage.IsClosed() {
+			return fmt.Errorf("%w: %s", ErrTabClosed, tabPage.ID())
+		}
+	}
+	return nil
+}
+
+// Acquire 借出一个浏览器：优先复用空闲且健康的成员，池未满时启动新成员，否则等待其他调用方归还
+func (p *Pool) Acquire(ctx context.Context) (*Lease, error) {
+	for {
+		p.locker.Lock()
+		if p.closed {
+			p.locker.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		// 1. 复用空闲成员，不健康的直接关闭
+		if n := len(p.idle); n > 0 {
+			member := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.locker.Unlock()
+			if err := p.options.HealthCheck(member.browser); err != nil {
+				log.Printf("浏览器 #%d 不可用，关闭后重新启动: %v", member.seq, err)
+				p.destroy(member)
+				continue
+			}
+			return newLease(p, member), nil
+		}
+
+		// 2. 池未满时启动新成员，启动期间占用一个名额
+		if p.created < p.options.Size {
+			p.created++
+			p.seq++
+			seq := p.seq
+			p.locker.Unlock()
+			member, err := p.start(seq)
+			if err != nil {
+				p.locker.Lock()
+				p.created--
+				p.notify()
+				p.locker.Unlock()
+				return nil, err
+			}
+			return newLease(p, member), nil
+		}
+
+		// 3. 等待归还或关闭
+		wakeup := p.wakeup
+		p.locker.Unlock()
+		select {
+		case <-wakeup:
+		case <-ctx.Done():
+			return nil, wrapError(ctx.Err())
+		}
+	}
+}
+
+// start 启动一个成员，用户数据目录按序号拆分，避免多个浏览器争用同一个目录
+func (p *Pool) start(seq int) (*poolMember, error) {
+	options := p.options.Browser
+	if options.UserDataDir != "" {
+		options.UserDataDir = filepath.Join(options.UserDataDir, fmt.Sprintf("pool-%d", seq))
+	}
+	b, err := p.options.NewBrowser(&options)
+	if err != nil {
+		return nil, fmt.Errorf("无法启动浏览器 #%d: %w", seq, err)
+	}
+	log.Printf("浏览器池已启动浏览器 #%d", seq)
+	return &poolMember{seq: seq, browser: b}, nil
+}
+
+// notify 唤醒所有等待的 Acquire，调用时需持有锁
+func (p *Pool) notify() {
+	close(p.wakeup)
+	p.wakeup = make(chan struct{})
+}
+
+func (p *Pool) destroy(member *poolMember) {
+	if err := member.browser.Close(); err != nil {
+		log.Printf("关闭浏览器 #%d 失败: %v", member.seq, err)
+	}
+	p.locker.Lock()
+	p.created--
+	p.notify()
+	p.locker.Unlock()
+}
+
+// release 归还成员：关闭借用期间打开的标签页，只保留第一个标签页并回到空白页
+func (p *Pool) release(member *poolMember) {
+	if err := resetBrowser(member.browser); err != nil {
+		log.Printf("重置浏览器 #%d 失败，不再复用: %v", member.seq, err)
+		p.destroy(member)
+		return
+	}
+
+	p.locker.Lock()
+	if p.closed {
+		p.locker.Unlock()
+		p.destroy(member)
+		return
+	}
+	p.idle = append(p.idle, member)
+	p.notify()
+	p.locker.Unlock()
+}
+
+func resetBrowser(b Browser) error {
+	tabPages := b.TabPages()
+	if len(tabPages) == 0 {
+		return fmt.Errorf("浏览器没有任何标签页")
+	}
+	for _, tabPage := range tabPages[1:] {
+		if err := b.CloseTabPage(tabPage.ID()); err != nil {
+			return err
+		}
+	}
+	return tabPages[0].Goto("about:blank")
+}
+
+// Close 关闭空闲的浏览器，借出中的浏览器在归还时关闭
+func (p *Pool) Close() error {
+	p.locker.Lock()
+	if p.closed {
+		p.locker.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.notify()
+	p.locker.Unlock()
+
+	var errs []error
+	for _, member := range idle {
+		if err := member.browser.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭浏览器 #%d 失败: %w", member.seq, err))
+		}
+		p.locker.Lock()
+		p.created--
+		p.locker.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Lease 借出的浏览器，用完后必须调用 Release 归还，或在浏览器状态异常时调用 Discard 丢弃
+type Lease struct {
+	Browser
+	pool   *Pool
+	member *poolMember
+	once   sync.Once
+}
+
+func newLease(p *Pool, member *poolMember) *Lease {
+	return &Lease{Browser: member.browser, pool: p, member: member}
+}
+
+// Seq 浏览器在池中的序号，从 1 开始，便于日志区分
+func (l *Lease) Seq() int {
+	return l.member.seq
+}
+
+// Release 归还浏览器，可重复调用
+func (l *Lease) Release() {
+	l.once.Do(func() {
+		l.pool.release(l.member)
+	})
+}
+
+// Discard 关闭浏览器而不归还，池会在需要时启动新的浏览器，可重复调用
+func (l *Lease) Discard() {
+	l.once.Do(func() {
+		l.pool.destroy(l.member)
+	})
+}
+
+// Close 等同于 Release，避免借用方误关闭池中的浏览器
+func (l *Lease) Close() error {
+	l.Release()
+	return nil
+}
@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// edgeProcess 记录由本程序启动的浏览器进程，关闭时只终止自己的进程树
+type edgeProcess struct {
+	cmd         *exec.Cmd
+	userDataDir string // 专用的用户数据目录
+	removeDir   bool   // 关闭后是否删除用户数据目录（临时目录）
+	done        chan struct{}
+	exitErr     error
+	once        sync.Once
+}
+
+func newEdgeProcess(cmd *exec.Cmd, userDataDir string, removeDir bool) *edgeProcess {
+	p := &edgeProcess{
+		cmd:         cmd,
+		userDataDir: userDataDir,
+		removeDir:   removeDir,
+		done:        make(chan struct{}),
+	}
+	// 回收子进程，避免僵尸进程
+	go func() {
+		p.exitErr = cmd.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// prepareUserDataDir 返回浏览器使用的用户数据目录，未指定时创建临时目录
+func prepareUserDataDir(userDataDir string) (string, bool, error) {
+	if userDataDir != "" {
+		if err := os.MkdirAll(userDataDir, os.ModePerm); err != nil {
+			return "", false, fmt.Errorf("无法创建用户数据目录: %v", err)
+		}
+		return userDataDir, false, nil
+	}
+	dir, err := os.MkdirTemp("", "rpa-edge-")
+	if err != nil {
+		return "", false, fmt.Errorf("无法创建临时用户数据目录: %v", err)
+	}
+	return dir, true, nil
+}
+
+func (p *edgeProcess) Pid() int {
+	return p.cmd.Process.Pid
+}
+
+// Exited 浏览器进程是否已退出
+func (p *edgeProcess) Exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Terminate 终止浏览器进程树并清理临时用户数据目录，可重复调用
+func (p *edgeProcess) Terminate() error {
+	var err error
+	p.once.Do(func() {
+		if !p.Exited() {
+			if err = killProcessTree(p.cmd); err != nil {
+				log.Printf("终止浏览器进程 %d 失败: %v", p.Pid(), err)
+			}
+			select {
+			case <-p.done:
+				log.Printf("浏览器进程 %d 已退出", p.Pid())
+			case <-time.After(5 * time.Second):
+				log.Printf("等待浏览器进程 %d 退出超时", p.Pid())
+			}
+		}
+
+		if p.removeDir {
+			// 浏览器退出后文件句柄可能尚未释放，多尝试几次
+			for i := range 5 {
+				if e := os.RemoveAll(p.userDataDir); e == nil {
+					break
+				} else if i == 4 {
+					log.Printf("删除临时用户数据目录失败: %v", e)
+				}
+				time.Sleep(500 * time.Millisecond)
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package browser
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让浏览器在独立的进程组中运行，便于整体终止
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessTree 终止浏览器所在的整个进程组
+func killProcessTree(cmd *exec.Cmd) error {
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+	return syscall.Kill(-pgid, syscall.SIGKILL)
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package browser
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup 让浏览器在独立的进程组中运行，便于整体终止
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// killProcessTree 终止浏览器进程及其所有子进程，不影响用户自己打开的 Edge 窗口
+func killProcessTree(cmd *exec.Cmd) error {
+	output, err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("taskkill 失败: %v, 输出: %s", err, string(output))
+	}
+	return nil
+}
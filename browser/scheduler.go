@@ -0,0 +1,236 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded 站点当天的操作次数已用完
+var ErrQuotaExceeded = errors.New("已达到站点当天的操作上限")
+
+// SitePolicy 一个站点的访问节奏
+type SitePolicy struct {
+	Domain      string        `yaml:"domain"`       // 站点域名，包含其所有子域名，如 "nmpa.gov.cn"；为空时作为其他站点的默认策略
+	MinInterval time.Duration `yaml:"min_interval"` // 两次操作之间的最小间隔
+	Jitter      time.Duration `yaml:"jitter"`       // 在最小间隔上随机增加 0 ~ Jitter，避免固定的操作节奏
+	DailyQuota  int           `yaml:"daily_quota"`  // 每天最多的操作次数，为 0 时不限
+	QuietHours  string        `yaml:"quiet_hours"`  // 不访问的时段，如 "23:00-07:00"，期间的操作等待到时段结束
+}
+
+// siteState 一个站点的排队状态
+type siteState struct {
+	policy    SitePolicy
+	quietFrom time.Duration // 静默时段的开始，距零点的时长，与 quietTo 相同时没有静默时段
+	quietTo   time.Duration
+	next      time.Time // 下一次操作最早的时间
+	day       string    // count 所属的日期
+	count     int       // 当天已预约的操作次数
+}
+
+// Scheduler 按站点控制浏览器操作的节奏：导航、点击和打开详情页前调用 Wait，
+// 同一站点的操作按最小间隔加随机抖动依次排队，静默时段内等待，当天次数用完后返回 ErrQuotaExceeded。
+// 同一个 Scheduler 可由多个标签页和浏览器共用，并发的操作也会依次间隔
+type Scheduler struct {
+	sites  []*siteState // 按域名从长到短排列，默认策略在最后
+	store  SessionStore
+	locker sync.Mutex
+}
+
+// NewScheduler 创建调度器，store 不为空时当天的操作次数保存在其中，重启后继续计数
+func NewScheduler(policies []SitePolicy, store SessionStore) (*Scheduler, error) {
+	s := &Scheduler{store: store}
+	domains := make(map[string]bool)
+	for _, policy := range policies {
+		policy.Domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(policy.Domain)), ".")
+		if domains[policy.Domain] {
+			return nil, fmt.Errorf("站点 %s 的访问策略重复", policy.Domain)
+		}
+		domains[policy.Domain] = true
+		if policy.MinInterval < 0 || policy.Jitter < 0 || policy.DailyQuota < 0 {
+			return nil, fmt.Errorf("站点 %s 的访问策略不能为负数", policy.Domain)
+		}
+		site := &siteState{policy: policy}
+		if policy.QuietHours != "" {
+			from, to, err := parseQuietHours(policy.QuietHours)
+			if err != nil {
+				return nil, fmt.Errorf("站点 %s 的静默时段 %s 无效: %w", policy.Domain, policy.QuietHours, err)
+			}
+			site.quietFrom, site.quietTo = from, to
+		}
+		s.sites = append(s.sites, site)
+	}
+	slices.SortStableFunc(s.sites, func(a, b *siteState) int {
+		return len(b.policy.Domain) - len(a.policy.Domain)
+	})
+	return s, nil
+}
+
+// parseQuietHours 解析 "HH:MM-HH:MM"，结束早于开始时表示跨零点
+func parseQuietHours(value string) (time.Duration, time.Duration, error) {
+	start, end, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("格式应为 HH:MM-HH:MM")
+	}
+	from, err := time.Parse("15:04", strings.TrimSpace(start))
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := time.Parse("15:04", strings.TrimSpace(end))
+	if err != nil {
+		return 0, 0, err
+	}
+	midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	return from.Sub(midnight), to.Sub(midnight), nil
+}
+
+// Wait 在对 target（URL 或主机名）操作前调用，按站点策略等待；没有匹配策略或 target 为本地页面时立即返回。
+// s 为 nil 时不做任何事，便于未配置调度器的浏览器直接调用
+func (s *Scheduler) Wait(ctx context.Context, target string) error {
+	if s == nil {
+		return nil
+	}
+	host := targetHost(target)
+	if host == "" {
+		return nil
+	}
+	site := s.match(host)
+	if site == nil {
+		return nil
+	}
+	for {
+		delay, reserved, err := s.reserve(site, time.Now())
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			log.Printf("站点 %s 处于静默时段 %s，等待 %v", site.policy.Domain, site.policy.QuietHours, delay.Round(time.Second))
+		}
+		if err = sleepContext(ctx, delay); err != nil {
+			return wrapError(err)
+		}
+		if reserved {
+			return nil
+		}
+	}
+}
+
+func (s *Scheduler) match(host string) *siteState {
+	for _, site := range s.sites {
+		if site.policy.Domain == "" || matchDomain(host, site.policy.Domain) {
+			return site
+		}
+	}
+	return nil
+}
+
+// reserve 为一次操作预约时间，返回需要等待的时长；处于静默时段时不预约，返回到时段结束的时长
+func (s *Scheduler) reserve(site *siteState, now time.Time) (time.Duration, bool, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if wait := site.quietRemaining(now); wait > 0 {
+		return wait, false, nil
+	}
+
+	policy := &site.policy
+	if policy.DailyQuota > 0 {
+		day := now.Format("20060102")
+		if site.day != day {
+			site.day = day
+			site.count = s.loadCount(policy.Domain, day)
+		}
+		if site.count >= policy.DailyQuota {
+			return 0, false, fmt.Errorf("%w: %s 每天 %d 次", ErrQuotaExceeded, policy.Domain, policy.DailyQuota)
+		}
+		site.count++
+		s.saveCount(policy.Domain, day, site.count)
+	}
+
+	at := now
+	if site.next.After(at) {
+		at = site.next
+	}
+	interval := policy.MinInterval
+	if policy.Jitter > 0 {
+		interval += rand.N(policy.Jitter)
+	}
+	site.next = at.Add(interval)
+	return at.Sub(now), true, nil
+}
+
+// quietRemaining 处于静默时段时返回到时段结束的时长，否则返回 0
+func (site *siteState) quietRemaining(now time.Time) time.Duration {
+	from, to := site.quietFrom, site.quietTo
+	if from == to {
+		return 0
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+	switch {
+	case from < to && offset >= from && offset < to:
+		return to - offset
+	case from > to && offset >= from: // 跨零点，当前在零点前
+		return 24*time.Hour - offset + to
+	case from > to && offset < to: // 跨零点，当前在零点后
+		return to - offset
+	}
+	return 0
+}
+
+func quotaKey(domain string, day string) string {
+	return "browser.scheduler." + domain + "." + day
+}
+
+func (s *Scheduler) loadCount(domain string, day string) int {
+	if s.store == nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(s.store.Get(quotaKey(domain, day)))
+	return count
+}
+
+func (s *Scheduler) saveCount(domain string, day string, count int) {
+	if s.store == nil {
+		return
+	}
+	s.store.SetEx(quotaKey(domain, day), strconv.Itoa(count), time.Now().Add(48*time.Hour).Unix())
+}
+
+// targetHost 取 URL 的主机名，target 不是 URL 时视为主机名，about:blank 等本地页面返回空
+func targetHost(target string) string {
+	if strings.HasPrefix(target, "about:") {
+		return ""
+	}
+	if !strings.Contains(target, "://") {
+		return strings.ToLower(target)
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回 ctx 的错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,264 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultSessionTTL 会话的默认有效期
+const DefaultSessionTTL = 12 * time.Hour
+
+// SessionStore 会话的持久化存储，lts.Storage() 返回的 *lts.LocalStorage 满足该接口
+type SessionStore interface {
+	Get(key string) string
+	SetEx(key string, value string, expiredAt int64)
+	Remove(keys ...string) int
+}
+
+// SessionOptions 会话保存和恢复的配置，启动时恢复 Domains 中各站点未过期的会话，关闭浏览器时保存
+type SessionOptions struct {
+	Store   SessionStore
+	Domains []string      // 需要保存会话的站点，如 "nmpa.gov.cn"，包含其所有子域名
+	TTL     time.Duration // 会话有效期，为 0 时使用 DefaultSessionTTL
+}
+
+// sessionState 一个站点的会话：Cookies 以及各个源的 localStorage、sessionStorage
+type sessionState struct {
+	Domain  string               `json:"domain"`
+	SavedAt int64                `json:"savedAt"`
+	Cookies []playwright.Cookie  `json:"cookies"`
+	Origins []originSessionState `json:"origins"`
+}
+
+type originSessionState struct {
+	Origin         string            `json:"origin"`
+	LocalStorage   map[string]string `json:"localStorage"`
+	SessionStorage map[string]string `json:"sessionStorage"`
+}
+
+func (s *sessionState) isEmpty() bool {
+	return len(s.Cookies) == 0 && len(s.Origins) == 0
+}
+
+func (s *sessionState) origin(origin string) *originSessionState {
+	for i := range s.Origins {
+		if s.Origins[i].Origin == origin {
+			return &s.Origins[i]
+		}
+	}
+	s.Origins = append(s.Origins, originSessionState{
+		Origin:         origin,
+		LocalStorage:   map[string]string{},
+		SessionStorage: map[string]string{},
+	})
+	return &s.Origins[len(s.Origins)-1]
+}
+
+func sessionKey(domain string) string {
+	return "browser.session." + domain
+}
+
+// matchDomain 判断主机名是否属于 domain 或其子域名，host 可以带 Cookie 的前导点
+func matchDomain(host string, domain string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), ".")
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func originHost(origin string) string {
+	_, host, found := strings.Cut(origin, "://")
+	if !found {
+		return ""
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+// InvalidateSession 删除指定站点已保存的会话，下次启动时不再恢复，已打开的浏览器不受影响，需要时配合 ClearLocalData 使用
+func InvalidateSession(store SessionStore, domains ...string) int {
+	if store == nil || len(domains) == 0 {
+		return 0
+	}
+	keys := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		keys = append(keys, sessionKey(domain))
+	}
+	count := store.Remove(keys...)
+	log.Printf("已删除 %d 个站点的会话: %s", count, strings.Join(domains, ", "))
+	return count
+}
+
+// loadSession 读取未过期的会话，不存在或无法解析时返回 nil
+func loadSession(store SessionStore, domain string) *sessionState {
+	value := store.Get(sessionKey(domain))
+	if value == "" {
+		return nil
+	}
+	state, err := ParseJson[sessionState](value)
+	if err != nil {
+		log.Printf("无法解析站点 %s 的会话: %v", domain, err)
+		return nil
+	}
+	return &state
+}
+
+// restoreSession 把保存的会话写回浏览器上下文：Cookies 直接添加，
+// localStorage 和 sessionStorage 通过初始化脚本在页面脚本运行前写入，只写入页面中还不存在的键
+func restoreSession(browserContext playwright.BrowserContext, options *SessionOptions) error {
+	if options == nil || options.Store == nil {
+		return nil
+	}
+
+	var cookies []playwright.OptionalCookie
+	var origins []originSessionState
+	for _, domain := range options.Domains {
+		state := loadSession(options.Store, domain)
+		if state == nil {
+			continue
+		}
+		for _, c := range state.Cookies {
+			cookie := playwright.OptionalCookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   playwright.String(c.Domain),
+				Path:     playwright.String(c.Path),
+				HttpOnly: playwright.Bool(c.HttpOnly),
+				Secure:   playwright.Bool(c.Secure),
+				SameSite: c.SameSite,
+			}
+			if c.Expires > 0 {
+				cookie.Expires = playwright.Float(c.Expires)
+			}
+			cookies = append(cookies, cookie)
+		}
+		origins = append(origins, state.Origins...)
+		log.Printf("恢复站点 %s 的会话，保存于 %s", domain, time.Unix(state.SavedAt, 0).Format(time.DateTime))
+	}
+
+	if len(cookies) > 0 {
+		if err := browserContext.AddCookies(cookies); err != nil {
+			return fmt.Errorf("无法恢复 Cookies: %v", err)
+		}
+	}
+	if len(origins) > 0 {
+		data, err := Stringify(origins)
+		if err != nil {
+			return err
+		}
+		script := fmt.Sprintf(`(() => {
+			const origins = %s;
+			for (const o of origins) {
+				if (o.origin !== location.origin) continue;
+				for (const [storage, items] of [[localStorage, o.localStorage], [sessionStorage, o.sessionStorage]]) {
+					for (const [k, v] of Object.entries(items || {})) {
+						if (storage.getItem(k) === null) storage.setItem(k, v);
+					}
+				}
+			}
+		})();`, data)
+		if err = browserContext.AddInitScript(playwright.Script{Content: playwright.String(script)}); err != nil {
+			return fmt.Errorf("无法恢复本地存储: %v", err)
+		}
+	}
+	return nil
+}
+
+// collectSession 从浏览器上下文和打开的标签页中收集各站点的会话，sessionStorage 只能从打开的标签页读取
+func collectSession(browserContext playwright.BrowserContext, pages []playwright.Page, domains []string) (map[string]*sessionState, error) {
+	now := time.Now().Unix()
+	states := make(map[string]*sessionState, len(domains))
+	for _, domain := range domains {
+		states[domain] = &sessionState{Domain: domain, SavedAt: now}
+	}
+	lookup := func(host string) *sessionState {
+		for _, domain := range domains {
+			if matchDomain(host, domain) {
+				return states[domain]
+			}
+		}
+		return nil
+	}
+
+	storage, err := browserContext.StorageState()
+	if err != nil {
+		return nil, fmt.Errorf("无法读取浏览器存储: %v", err)
+	}
+	for _, c := range storage.Cookies {
+		if state := lookup(c.Domain); state != nil {
+			state.Cookies = append(state.Cookies, c)
+		}
+	}
+	for _, o := range storage.Origins {
+		state := lookup(originHost(o.Origin))
+		if state == nil || len(o.LocalStorage) == 0 {
+			continue
+		}
+		origin := state.origin(o.Origin)
+		for _, item := range o.LocalStorage {
+			origin.LocalStorage[item.Name] = item.Value
+		}
+	}
+
+	for _, page := range pages {
+		if page.IsClosed() {
+			continue
+		}
+		result, err := page.Evaluate(`() => ({ origin: location.origin, items: Object.assign({}, sessionStorage) })`)
+		if err != nil {
+			log.Printf("读取 %s 的 sessionStorage 失败: %v", page.URL(), err)
+			continue
+		}
+		m, ok := result.(map[string]any)
+		if !ok {
+			continue
+		}
+		originName, _ := m["origin"].(string)
+		items, _ := m["items"].(map[string]any)
+		state := lookup(originHost(originName))
+		if state == nil || len(items) == 0 {
+			continue
+		}
+		origin := state.origin(originName)
+		for k, v := range items {
+			origin.SessionStorage[k] = fmt.Sprint(v)
+		}
+	}
+	return states, nil
+}
+
+// saveSession 保存各站点的会话；站点没有任何 Cookies 和本地存储时（例如刚调用过 ClearLocalData）保留已保存的会话
+func saveSession(browserContext playwright.BrowserContext, pages []playwright.Page, options *SessionOptions) error {
+	if options == nil || options.Store == nil || len(options.Domains) == 0 {
+		return nil
+	}
+	states, err := collectSession(browserContext, pages, options.Domains)
+	if err != nil {
+		return err
+	}
+
+	ttl := options.TTL
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	expiredAt := time.Now().Add(ttl).Unix()
+	for _, domain := range options.Domains {
+		state := states[domain]
+		if state.isEmpty() {
+			continue
+		}
+		value, err := Stringify(state)
+		if err != nil {
+			return err
+		}
+		options.Store.SetEx(sessionKey(domain), value, expiredAt)
+		log.Printf("已保存站点 %s 的会话: %d 个 Cookies, %d 个源", domain, len(state.Cookies), len(state.Origins))
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// StealthEnv 生成补丁脚本时可用的浏览器信息
+type StealthEnv struct {
+	DebugPort int      // 远程调试端口，未使用时为 0
+	UserAgent string   // 统一使用的 UA
+	Languages []string // 统一使用的语言列表
+}
+
+// StealthPatch 反检测补丁，Script 返回在页面自身脚本之前执行的初始化脚本，返回空字符串表示跳过
+type StealthPatch interface {
+	Name() string
+	Script(env *StealthEnv) string
+}
+
+// StealthProfile 反检测配置，注册在 BrowserContext 上，对所有新标签页（包括 OpenInNewTab 捕获的弹出页）生效
+type StealthProfile struct {
+	Patches   []StealthPatch
+	UserAgent string   // 为空时使用浏览器自身的 UA（去掉 HeadlessChrome 标记）
+	Languages []string // 为空时不修改语言
+}
+
+// DefaultStealthProfile 默认配置：隐藏调试端口、屏蔽 navigator.webdriver、统一 UA 和中文语言
+func DefaultStealthProfile() *StealthProfile {
+	return &StealthProfile{
+		Patches:   []StealthPatch{DebugPortPatch{}, WebdriverPatch{}, UserAgentPatch{}},
+		Languages: []string{"zh-CN", "zh"},
+	}
+}
+
+// applyStealth 将反检测补丁注册为上下文的初始化脚本，并让 HTTP 请求头与脚本中的 UA、语言保持一致
+func applyStealth(browserContext playwright.BrowserContext, profile *StealthProfile, debugPort int) error {
+	if profile == nil || len(profile.Patches) == 0 {
+		return nil
+	}
+
+	env := &StealthEnv{
+		DebugPort: debugPort,
+		UserAgent: profile.UserAgent,
+		Languages: profile.Languages,
+	}
+	if env.UserAgent == "" {
+		userAgent, err := detectUserAgent(browserContext)
+		if err != nil {
+			log.Printf("获取浏览器 UA 失败: %v", err)
+		}
+		env.UserAgent = strings.Replace(userAgent, "HeadlessChrome", "Chrome", 1)
+	}
+
+	names := make([]string, 0, len(profile.Patches))
+	for _, patch := range profile.Patches {
+		script := patch.Script(env)
+		if script == "" {
+			continue
+		}
+		if err := browserContext.AddInitScript(playwright.Script{Content: playwright.String(script)}); err != nil {
+			return fmt.Errorf("注册反检测补丁 %s 失败: %v", patch.Name(), err)
+		}
+		names = append(names, patch.Name())
+	}
+
+	headers := map[string]string{}
+	if env.UserAgent != "" {
+		headers["User-Agent"] = env.UserAgent
+	}
+	if len(env.Languages) > 0 {
+		headers["Accept-Language"] = acceptLanguage(env.Languages)
+	}
+	if len(headers) > 0 {
+		if err := browserContext.SetExtraHTTPHeaders(headers); err != nil {
+			return fmt.Errorf("设置请求头失败: %v", err)
+		}
+	}
+
+	log.Printf(">>>已注册反检测补丁: %s", strings.Join(names, ", "))
+	return nil
+}
+
+// detectUserAgent 打开一个临时页面读取浏览器自身的 UA
+func detectUserAgent(browserContext playwright.BrowserContext) (string, error) {
+	page, err := browserContext.NewPage()
+	if err != nil {
+		return "", err
+	}
+	defer page.Close()
+	userAgent, err := page.Evaluate("navigator.userAgent")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(userAgent), nil
+}
+
+// acceptLanguage 将语言列表转换为 Accept-Language 请求头，如 zh-CN,zh;q=0.9
+func acceptLanguage(languages []string) string {
+	parts := make([]string, 0, len(languages))
+	for i, language := range languages {
+		if i == 0 {
+			parts = append(parts, language)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s;q=%.1f", language, max(1-float64(i)/10, 0.1)))
+	}
+	return strings.Join(parts, ",")
+}
+
+func jsString(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// DebugPortPatch 过滤 Performance 条目中的调试端口地址，并拦截页面对调试端口的 WebSocket 探测
+type DebugPortPatch struct{}
+
+func (DebugPortPatch) Name() string {
+	return "debug-port"
+}
+
+func (DebugPortPatch) Script(env *StealthEnv) string {
+	if env.DebugPort <= 0 { // 未开启远程调试端口，无需拦截
+		return ""
+	}
+	return fmt.Sprintf(`(() => {
+	const debugPort = "%d";
+	const isDebugUrl = (url) => url.includes("127.0.0.1:" + debugPort) || url.includes("localhost:" + debugPort);
+	const originalGetEntries = performance.getEntries;
+	const originalGetEntriesByType = performance.getEntriesByType;
+	const OriginalWebSocket = window.WebSocket;
+
+	// 过滤 Performance 条目
+	performance.getEntries = function() {
+		return originalGetEntries.call(this).filter(entry => !isDebugUrl(entry.name));
+	};
+	performance.getEntriesByType = function(type) {
+		return originalGetEntriesByType.call(this, type).filter(entry => !isDebugUrl(entry.name));
+	};
+
+	// 拦截 WebSocket 连接
+	window.WebSocket = function(urlArg, protocols) {
+		// 统一处理 URL 格式
+		const url = urlArg instanceof URL ? urlArg.href : urlArg;
+
+		// 检测目标地址
+		if (typeof url === 'string' && isDebugUrl(url)) {
+			// 创建虚假的 WebSocket 对象
+			const fakeWs = new OriginalWebSocket('ws://invalid-host-' + Date.now());
+
+			// 立即关闭连接并修改状态
+			Object.defineProperty(fakeWs, 'readyState', {
+				value: OriginalWebSocket.CLOSED,
+				writable: false
+			});
+
+			// 设置异步触发确保执行顺序
+			setTimeout(() => {
+				const errorEvent = new Event('error');
+				if (typeof fakeWs.onerror === 'function') {
+					fakeWs.onerror(errorEvent);
+				}
+				fakeWs.dispatchEvent(errorEvent);
+				fakeWs.close();
+			}, 0);
+
+			return fakeWs;
+		}
+
+		// 正常连接处理
+		return protocols ? new OriginalWebSocket(urlArg, protocols) : new OriginalWebSocket(urlArg);
+	};
+
+	// 保持原型链完整
+	window.WebSocket.prototype = OriginalWebSocket.prototype;
+	Object.assign(window.WebSocket, {
+		CONNECTING: OriginalWebSocket.CONNECTING,
+		OPEN: OriginalWebSocket.OPEN,
+		CLOSING: OriginalWebSocket.CLOSING,
+		CLOSED: OriginalWebSocket.CLOSED
+	});
+})();`, env.DebugPort)
+}
+
+// WebdriverPatch 让 navigator.webdriver 与普通浏览器一致
+type WebdriverPatch struct{}
+
+func (WebdriverPatch) Name() string {
+	return "webdriver"
+}
+
+func (WebdriverPatch) Script(env *StealthEnv) string {
+	return `(() => {
+	Object.defineProperty(Navigator.prototype, 'webdriver', {
+		get: () => false,
+		configurable: true
+	});
+})();`
+}
+
+// UserAgentPatch 让 navigator.userAgent、appVersion、language(s) 与请求头保持一致
+type UserAgentPatch struct{}
+
+func (UserAgentPatch) Name() string {
+	return "user-agent"
+}
+
+func (UserAgentPatch) Script(env *StealthEnv) string {
+	if env.UserAgent == "" && len(env.Languages) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`(() => {
+	const userAgent = %s;
+	const languages = %s;
+	const define = (name, value) => Object.defineProperty(Navigator.prototype, name, {
+		get: () => value,
+		configurable: true
+	});
+	if (userAgent) {
+		define('userAgent', userAgent);
+		define('appVersion', userAgent.replace(/^Mozilla\//, ''));
+	}
+	if (languages && languages.length > 0) {
+		define('language', languages[0]);
+		define('languages', Object.freeze([...languages]));
+	}
+})();`, jsString(env.UserAgent), jsString(env.Languages))
+}
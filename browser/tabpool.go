@@ -0,0 +1,118 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// TabTask 在新标签页中完成的一个任务，例如采集列表中一行的详情页
+type TabTask[T any] struct {
+	Open    func() error                                        // 在源标签页上打开新标签页的动作，例如点击详情按钮
+	URL     string                                              // Open 为空时在源标签页中用 window.open 打开的地址
+	Timeout time.Duration                                       // 等待新标签页的超时，为 0 时使用 TabPoolOptions.OpenTimeout
+	Parse   func(ctx context.Context, tab TabPageV2) (T, error) // 在新标签页中解析，返回后标签页即被关闭
+}
+
+// TabResult 一个任务的结果，Index 为任务在 tasks 中的下标
+type TabResult[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// TabPoolOptions 并发标签页参数
+type TabPoolOptions struct {
+	Concurrency int           // 最多同时打开的标签页数量，默认为 3
+	OpenTimeout time.Duration // 等待新标签页的默认超时，默认为 10 秒
+	TabID       string        // 新标签页 ID 的前缀，后接任务序号，默认为 "详情页"
+	Scheduler   *Scheduler    // 打开每个新标签页前按源标签页所在站点的节奏排队，为空时不排队
+}
+
+// FetchInTabs 用有限数量的新标签页并发执行 tasks，结果按 tasks 的顺序返回。
+// 新标签页由源标签页的 OpenInNewTab 捕获，同一浏览器同一时间只能捕获一个，因此打开是串行的，
+// 等待和解析详情在各自的标签页中并发进行。ctx 取消后未开始的任务以 ctx 的错误返回
+func FetchInTabs[T any](ctx context.Context, b Browser, source TabPage, tasks []TabTask[T], options *TabPoolOptions) []TabResult[T] {
+	opts := TabPoolOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 3
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 10 * time.Second
+	}
+	if opts.TabID == "" {
+		opts.TabID = "详情页"
+	}
+
+	results := make([]TabResult[T], len(tasks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(opts.Concurrency, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				value, err := runTabTask(ctx, b, source.V2(), fmt.Sprintf("%s-%d", opts.TabID, i+1), &tasks[i], &opts)
+				results[i] = TabResult[T]{Index: i, Value: value, Err: err}
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(tasks); next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i := next; i < len(tasks); i++ {
+		results[i] = TabResult[T]{Index: i, Err: wrapError(ctx.Err())}
+	}
+	return results
+}
+
+// runTabTask 打开新标签页执行一个任务，无论成功与否都会关闭新标签页
+func runTabTask[T any](ctx context.Context, b Browser, source TabPageV2, id string, task *TabTask[T], opts *TabPoolOptions) (T, error) {
+	var zero T
+	open := task.Open
+	if open == nil {
+		if task.URL == "" {
+			return zero, fmt.Errorf("任务 %s 没有打开新标签页的动作或地址", id)
+		}
+		open = func() error {
+			_, err := source.Evaluate(ctx, "url => { window.open(url, '_blank') }", task.URL)
+			return err
+		}
+	}
+	timeout := task.Timeout
+	if timeout <= 0 {
+		timeout = opts.OpenTimeout
+	}
+
+	// 排队的时间不计入等待新标签页的超时
+	if err := opts.Scheduler.Wait(ctx, source.URL()); err != nil {
+		return zero, err
+	}
+	openCtx, cancel := context.WithTimeout(ctx, timeout)
+	tab, err := source.OpenInNewTab(openCtx, id, open)
+	cancel()
+	if err != nil {
+		return zero, err
+	}
+	defer func() {
+		if err := b.CloseTabPage(id); err != nil {
+			log.Printf("关闭标签页 %s 失败: %v", id, err)
+		}
+	}()
+	return task.Parse(ctx, tab)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
@@ -69,132 +70,70 @@ func connectToExistingEdge(pw *playwright.Playwright, port string) (playwright.B
 	return nil, false
 }
 
-func killEdgeProcesses() error {
-	// 检查进程是否存在
-	cmd := exec.Command("tasklist", "/FI", "IMAGENAME eq msedge.exe")
-	output, err := cmd.CombinedOutput()
-	if err != nil || !strings.Contains(string(output), "msedge.exe") {
-		fmt.Println("未找到 msedge.exe 进程")
-		return nil
-	}
-
-	// 终止进程
-	cmd = exec.Command("taskkill", "/F", "/IM", "msedge.exe")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
-			log.Printf("命令退出码: %d, 输出: %s", exitCode, string(output))
-			switch exitCode {
-			case 1:
-				return fmt.Errorf("权限不足，请以管理员身份运行")
-			case 128:
-				return fmt.Errorf("未找到 msedge.exe 进程")
-			default:
-				return fmt.Errorf("未知错误，退出码: %d, 输出: %s", exitCode, string(output))
-			}
-		}
-		return fmt.Errorf("终止 msedge 失败: %v, 输出: %s", err, string(output))
-	}
-	fmt.Println("成功终止 msedge 进程")
-	return nil
-}
-
-// startNewEdge 启动新的 Edge 实例
-func startNewEdge(edgePath, port string) (*exec.Cmd, error) {
+// startNewEdge 使用专用的用户数据目录启动新的 Edge 实例
+func startNewEdge(edgePath, port, userDataDir string) (*exec.Cmd, error) {
 	cmd := exec.Command(edgePath,
 		"--new-window",
 		"about:blank",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--user-data-dir="+userDataDir,
 		"--remote-debugging-port="+port,
 		"--remote-allow-origins=http://127.0.0.1:"+port)
+	setProcessGroup(cmd)
 	err := cmd.Start()
 	if err != nil {
 		return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
 	}
-	log.Printf("Edge 浏览器已启动，调试端口: %s", port)
+	log.Printf("Edge 浏览器已启动，进程: %d, 调试端口: %s", cmd.Process.Pid, port)
 	return cmd, nil
 }
 
-func block_debug_port_detector(p playwright.Page, port int) error {
-	script := fmt.Sprintf(`() => {
-		const debugPort = "%d";
-		const originalGetEntries = performance.getEntries;
-		const originalGetEntriesByType = performance.getEntriesByType;
-		const OriginalWebSocket = window.WebSocket;
-
-		// 过滤 Performance 条目
-        performance.getEntries = function() {
-            return originalGetEntries.call(this).filter(entry => 
-                !entry.name.includes("127.0.0.1:" + debugPort) && 
-                !entry.name.includes("localhost:" + debugPort)
-            );
-        };
-
-		performance.getEntriesByType = function(type){
-			return originalGetEntriesByType.call(this, type).filter(entry =>
-                !entry.name.includes("127.0.0.1:" + debugPort) && 
-                !entry.name.includes("localhost:" + debugPort)
-			);
-		};
-
-		// 拦截 WebSocket 连接
-		window.WebSocket = function(urlArg, protocols) {
-		// 统一处理 URL 格式
-		const url = urlArg instanceof URL ? urlArg.href : urlArg;
-		
-		// 检测目标地址
-		if (typeof url === 'string' && 
-			(url.includes("127.0.0.1:" + debugPort) || 
-			url.includes("localhost:" + debugPort))) {
-			
-			// 创建虚假的 WebSocket 对象
-			const fakeWs = new OriginalWebSocket('ws://invalid-host-' + Date.now());
-			
-			// 立即关闭连接并修改状态
-			Object.defineProperty(fakeWs, 'readyState', {
-			value: OriginalWebSocket.CLOSED,
-			writable: false
-			});
-			
-			// 强制触发错误事件
-			const errorEvent = new Event('error');
-			errorEvent.initEvent('error', false, false);
-			
-			// 设置异步触发确保执行顺序
-			setTimeout(() => {
-			if (typeof fakeWs.onerror === 'function') {
-				fakeWs.onerror(errorEvent);
-			}
-			fakeWs.dispatchEvent(errorEvent);
-			
-			fakeWs.close();
-			}, 0);
-			
-			return fakeWs;
-		}
-		
-		// 正常连接处理
-		return protocols ? 
-			new OriginalWebSocket(urlArg, protocols) : 
-			new OriginalWebSocket(urlArg);
-		};
+func listen_page_console_log(tab *EdgeTabPage) {
+	tab.page.On("console", func(message playwright.ConsoleMessage) {
+		log.Printf(">>> %s", message.Text())
+		tab.traceLocker.Lock()
+		defer tab.traceLocker.Unlock()
+		tab.consoleLog = appendLimited(tab.consoleLog, ConsoleEntry{
+			Time: time.Now(),
+			Type: message.Type(),
+			Text: message.Text(),
+		}, maxConsoleEntries)
+	})
+}
 
-		// 保持原型链完整
-		window.WebSocket.prototype = OriginalWebSocket.prototype;		
-	}`, port)
-	_, err := p.Evaluate(script)
-	if err != nil {
-		log.Printf("注入拦截脚本失败: %v", err)
-		return err
+func listen_page_network(tab *EdgeTabPage) {
+	record := func(entry NetworkEntry) {
+		tab.traceLocker.Lock()
+		defer tab.traceLocker.Unlock()
+		tab.networkLog = appendLimited(tab.networkLog, entry, maxNetworkEntries)
 	}
-
-	log.Printf(">>>已拦截Performance API和 WebSocket 请求")
-
-	return nil
+	tab.page.On("response", func(response playwright.Response) {
+		record(NetworkEntry{
+			Time:   time.Now(),
+			Method: response.Request().Method(),
+			URL:    response.URL(),
+			Status: response.Status(),
+		})
+	})
+	tab.page.On("requestfailed", func(request playwright.Request) {
+		entry := NetworkEntry{
+			Time:   time.Now(),
+			Method: request.Method(),
+			URL:    request.URL(),
+		}
+		if err := request.Failure(); err != nil {
+			entry.Failure = err.Error()
+		}
+		record(entry)
+	})
 }
 
-func listen_page_console_log(page playwright.Page) {
-	page.On("console", func(message playwright.ConsoleMessage) {
-		log.Printf(">>> %s", message.Text())
-	})
+// appendLimited 追加元素，超出上限时丢弃最旧的元素
+func appendLimited[T any](items []T, item T, limit int) []T {
+	items = append(items, item)
+	if len(items) > limit {
+		items = items[len(items)-limit:]
+	}
+	return items
 }
@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"net"
-	"os/exec"
-	"strings"
+	"path/filepath"
 	"time"
 
+	"rpa-yjj-api/browser"
+
 	"github.com/playwright-community/playwright-go"
 )
 
+// Edge 的可执行文件路径
+const edgePath = "C:\\Program Files (x86)\\Microsoft\\Edge\\Application\\msedge.exe"
+
 func removeByValue(slice []string, value string) []string {
 	for i, v := range slice {
 		if v == value {
@@ -20,282 +24,80 @@ func removeByValue(slice []string, value string) []string {
 	return slice // 未找到匹配值，返回原切片
 }
 
-// isPortAvailable 检查指定端口是否可用
-func isPortAvailable(port int) bool {
-	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
-	if err != nil {
-		return false
-	}
-	ln.Close()
-	return true
-}
-
-func getValidPort() (int, error) {
-	count := 0
-	startPort := 9222
-	endPort := 20000
-	for i := startPort; i < endPort; i++ {
-		// 随机选择一个端口
-		port := i
-		// 检查端口是否可用
-		if isPortAvailable(port) {
-			return port, nil
-		}
-		count++
-		fmt.Printf("端口 %d 被占用，尝试次数 %d/%d\n", port, i+1, count)
-	}
-	return 0, fmt.Errorf("在 %d 次尝试后未找到可用端口", count)
-}
-
-// connectToExistingEdge 检查是否有已运行的 Edge 实例并尝试连接
-func connectToExistingEdge(pw *playwright.Playwright, port string) (playwright.Browser, bool) {
-	// 尝试连接到默认调试端口
-	browser, err := pw.Chromium.ConnectOverCDP("http://127.0.0.1:" + port)
-	if err == nil {
-		return browser, true
-	}
-	return nil, false
-}
-
-// killEdgeProcesses 关闭所有 Edge 进程
-func killEdgeProcesses() error {
-	// 检查进程是否存在
-	cmd := exec.Command("tasklist", "/FI", "IMAGENAME eq msedge.exe")
-	output, err := cmd.CombinedOutput()
-	if err != nil || !strings.Contains(string(output), "msedge.exe") {
-		fmt.Println("未找到 msedge.exe 进程")
-		return nil
-	}
-
-	// 终止进程
-	cmd = exec.Command("taskkill", "/F", "/IM", "msedge.exe")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
-			log.Printf("命令退出码: %d, 输出: %s", exitCode, string(output))
-			switch exitCode {
-			case 1:
-				return fmt.Errorf("权限不足，请以管理员身份运行")
-			case 128:
-				return fmt.Errorf("未找到 msedge.exe 进程")
-			default:
-				return fmt.Errorf("未知错误，退出码: %d, 输出: %s", exitCode, string(output))
-			}
-		}
-		return fmt.Errorf("终止 msedge 失败: %v, 输出: %s", err, string(output))
-	}
-	fmt.Println("成功终止 msedge 进程")
-	return nil
-}
-
-// startNewEdge 启动新的 Edge 实例
-func startNewEdge(edgePath, port string) (*exec.Cmd, error) {
-	cmd := exec.Command(edgePath,
-		"--new-window",
-		"about:blank",
-		"--remote-debugging-port="+port,
-		"--remote-allow-origins=http://127.0.0.1:"+port)
-	err := cmd.Start()
-	if err != nil {
-		return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
-	}
-	return cmd, nil
-}
-
-func block_detector(p playwright.Page, port int) error {
-	script := fmt.Sprintf(`() => {
-		const debugPort = "%d";
-		const originalGetEntries = performance.getEntries;
-		const originalGetEntriesByType = performance.getEntriesByType;
-		const OriginalWebSocket = window.WebSocket;
-
-		// 过滤 Performance 条目
-        performance.getEntries = function() {
-            return originalGetEntries.call(this).filter(entry => 
-                !entry.name.includes("127.0.0.1:" + debugPort) && 
-                !entry.name.includes("localhost:" + debugPort)
-            );
-        };
-
-		performance.getEntriesByType = function(type){
-			return originalGetEntriesByType.call(this, type).filter(entry =>
-                !entry.name.includes("127.0.0.1:" + debugPort) && 
-                !entry.name.includes("localhost:" + debugPort)
-			);
-		};
-
-		// 拦截 WebSocket 连接
-		window.WebSocket = function(urlArg, protocols) {
-		// 统一处理 URL 格式
-		const url = urlArg instanceof URL ? urlArg.href : urlArg;
-		
-		// 检测目标地址
-		if (typeof url === 'string' && 
-			(url.includes("127.0.0.1:" + debugPort) || 
-			url.includes("localhost:" + debugPort))) {
-			
-			// 创建虚假的 WebSocket 对象
-			const fakeWs = new OriginalWebSocket('ws://invalid-host-' + Date.now());
-			
-			// 立即关闭连接并修改状态
-			Object.defineProperty(fakeWs, 'readyState', {
-			value: OriginalWebSocket.CLOSED,
-			writable: false
-			});
-			
-			// 强制触发错误事件
-			const errorEvent = new Event('error');
-			errorEvent.initEvent('error', false, false);
-			
-			// 设置异步触发确保执行顺序
-			setTimeout(() => {
-			if (typeof fakeWs.onerror === 'function') {
-				fakeWs.onerror(errorEvent);
-			}
-			fakeWs.dispatchEvent(errorEvent);
-			
-			fakeWs.close();
-			}, 0);
-			
-			return fakeWs;
-		}
-		
-		// 正常连接处理
-		return protocols ? 
-			new OriginalWebSocket(urlArg, protocols) : 
-			new OriginalWebSocket(urlArg);
-		};
-
-		// 保持原型链完整
-		window.WebSocket.prototype = OriginalWebSocket.prototype;		
-	}`, port)
-	_, err := p.Evaluate(script)
-	if err != nil {
-		log.Printf("注入拦截脚本失败: %v", err)
-		return err
-	}
-
-	log.Printf(">>>已拦截Performance API和 WebSocket 请求")
+// sessionDomains 需要跨次运行保存会话的站点
+var sessionDomains = []string{"nmpa.gov.cn"}
 
-	return nil
+// edgeOptions 采集时使用的浏览器启动参数，可在启动采集前由命令行参数修改
+var edgeOptions = browser.Options{
+	Mode:      browser.LaunchEdgeCDP,
+	EdgePath:  edgePath,
+	StartPort: 9222,
+	EndPort:   20000,
 }
 
+// PlaywrightEdge 以标签页栈的方式包装 browser.Browser，浏览器的启动和关闭由 browser 包负责
 type PlaywrightEdge struct {
-	port     int
-	pw       *playwright.Playwright
-	browser  playwright.Browser
-	context  playwright.BrowserContext
-	allPages map[string]playwright.Page
-	tabIds   []string
-	index    int
+	browser     browser.Browser
+	diagnostics *browser.Diagnostics
+	tabIds      []string
+	index       int
 }
 
 func NewPlaywrightEdge(port int) (*PlaywrightEdge, error) {
-	// 1. 找到系统中 Edge 的可执行文件路径
-	edgePath := "C:\\Program Files (x86)\\Microsoft\\Edge\\Application\\msedge.exe"
-
-	// 2. 获取一个可用端口，用于调试
-	var debugPort int
+	// 1. 确定调试端口范围
+	options := edgeOptions
 	if port > 0 {
-		debugPort = port
-	} else {
-		var err error
-		debugPort, err = getValidPort()
-		if err != nil {
-			return nil, fmt.Errorf("无法获取可用端口: %v", err)
-		}
+		options.StartPort, options.EndPort = port, port
 	}
 
-	// 3. 启动 Playwright
-	pw, err := playwright.Run()
+	// 2. 启动（或连接）浏览器，默认标签页由 browser 包创建
+	b, err := browser.StartBrowser(&options)
 	if err != nil {
-		return nil, fmt.Errorf("无法启动 Playwright: %v", err)
+		return nil, err
 	}
 
-	// 4. 尝试连接到已运行的 Edge 实例
-	browser, found := connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
-	if !found {
-		// 如果没有找到已运行的 Edge 实例，则关闭所有 Edge 进程并启动一个新实例
-		err = killEdgeProcesses()
-		if err != nil {
-			pw.Stop()
-			return nil, fmt.Errorf("无法关闭 Edge 进程: %v", err)
-		}
-
-		// 启动新的 Edge 实例
-		_, err = startNewEdge(edgePath, fmt.Sprintf("%d", debugPort))
-		if err != nil {
-			pw.Stop()
-			return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
-		}
+	// 3. 创建 PlaywrightEdge 实例
+	pe := &PlaywrightEdge{
+		browser:     b,
+		diagnostics: browser.NewDiagnostics(filepath.Join(get_app_root_dir(), "logs"), browser.NewRunID()),
+		tabIds:      []string{"default"},
+		index:       0,
+	}
+	return pe, nil
+}
 
-		// 等待片刻，确保浏览器启动
-		time.Sleep(2 * time.Second)
-		log.Printf("Edge 浏览器已通过系统命令启动，调试端口: %d\n", debugPort)
+// Diagnose 为每个标签页保存截图、HTML、控制台消息和网络请求，用于事后排查选择器失效等问题
+func (pe *PlaywrightEdge) Diagnose(reason string, cause error) {
+	pe.diagnostics.CaptureAll(pe.browser, reason, cause)
+}
 
-		// 连接到新启动的 Edge 实例
-		browser, found = connectToExistingEdge(pw, fmt.Sprintf("%d", debugPort))
-		if !found {
-			pw.Stop()
-			return nil, fmt.Errorf("无法连接到新启动的 Edge 实例")
+// DiagnosePage 只为 page 所在的标签页保存诊断信息。并发采集详情时其他标签页随时可能被关闭，
+// 详情页出错时不能像 Diagnose 那样遍历所有标签页
+func (pe *PlaywrightEdge) DiagnosePage(page playwright.Page, reason string, cause error) {
+	for _, tab := range pe.browser.TabPages() {
+		if tab.Page() != page {
+			continue
 		}
-	} else {
-		log.Printf("已找到正在运行的 Edge 实例，调试端口:%d\n", debugPort)
-	}
-
-	// 5. 获取浏览器上下文并关闭所有默认页面
-	contexts := browser.Contexts()
-	if len(contexts) == 0 {
-		browser.Close()
-		pw.Stop()
-		return nil, fmt.Errorf("未找到任何浏览器上下文")
-	}
-	browserContext := contexts[0]
-	pages := browserContext.Pages()
-	for _, p := range pages {
-		err = p.Close()
-		if err != nil {
-			log.Printf("关闭页面失败: %v", err)
+		if _, err := pe.diagnostics.Capture(tab, reason, cause); err != nil {
+			log.Printf("保存标签页 %s 的诊断信息失败: %v", tab.ID(), err)
 		}
+		return
 	}
-
-	// 6. 创建 PlaywrightEdge 实例
-	pe := &PlaywrightEdge{
-		port:     debugPort,
-		pw:       pw,
-		browser:  browser,
-		context:  browserContext,
-		allPages: make(map[string]playwright.Page),
-		tabIds:   []string{},
-		index:    0,
-	}
-
-	// 7. 创建一个默认页面
-	_, err = pe.NewPage("default", "about:blank")
-	if err != nil {
-		pe.Close()
-		return nil, err
-	}
-	pe.setCurrentPage("default")
-	return pe, nil
+	log.Printf("未找到出错的标签页，没有保存诊断信息: %s", reason)
 }
 
-func (pe *PlaywrightEdge) addPage(id string, page playwright.Page) {
-	pe.allPages[id] = page
+func (pe *PlaywrightEdge) addPage(id string) {
 	pe.tabIds = append(pe.tabIds, id)
 }
 
 func (pe *PlaywrightEdge) removePage(id string) {
-	p := pe.GetPage(id)
-	if p == nil {
+	if pe.browser.FindTabPage(id) == nil {
 		return
 	}
-	err := p.Close()
+	err := pe.browser.CloseTabPage(id)
 	if err != nil {
 		log.Printf("关闭页面失败: %v", err)
 	}
-	delete(pe.allPages, id)
 	pe.tabIds = removeByValue(pe.tabIds, id)
 	if len(pe.tabIds) == 0 {
 		pe.NewPage("default", "about:blank")
@@ -304,7 +106,7 @@ func (pe *PlaywrightEdge) removePage(id string) {
 		if pe.index >= len(pe.tabIds) {
 			pe.index = len(pe.tabIds) - 1
 		}
-		pe.allPages[pe.tabIds[pe.index]].BringToFront()
+		pe.browser.SwitchToTabPage(pe.tabIds[pe.index])
 	}
 }
 
@@ -315,7 +117,7 @@ func (pe *PlaywrightEdge) setCurrentPage(id string) {
 				return
 			}
 			pe.index = i
-			pe.allPages[id].BringToFront()
+			pe.browser.SwitchToTabPage(id)
 			log.Printf("切换到标签页: %s", pe.tabIds[pe.index])
 			return
 		}
@@ -324,54 +126,36 @@ func (pe *PlaywrightEdge) setCurrentPage(id string) {
 
 func (pe *PlaywrightEdge) Close() {
 	log.Println("正在关闭 Edge 浏览器...")
-	for _, p := range pe.allPages {
-		err := p.Close()
-		if err != nil {
-			log.Printf("关闭页面失败: %v", err)
-		}
+	err := pe.browser.Close()
+	if err != nil {
+		log.Printf("关闭浏览器失败: %v", err)
+		return
 	}
-	log.Println("已关闭所有页面")
-	pe.context.Close()
-	log.Println("已关闭浏览器上下文")
-	pe.browser.Close()
 	log.Println("已关闭浏览器")
-	pe.pw.Stop()
-	log.Println("已关闭 Playwright")
 }
 
 func (pe *PlaywrightEdge) NewPage(id string, url string) (playwright.Page, error) {
-	if url == "" {
-		url = "about:blank"
-	}
-	// 创建一个新的空白页面
-	page, err := pe.context.NewPage()
-	if err != nil {
-		return nil, fmt.Errorf("无法创建新页面: %v", err)
+	tabPage := pe.browser.NewTabPage(id, url)
+	if tabPage == nil {
+		return nil, fmt.Errorf("无法创建新页面: %s", id)
 	}
-
-	// 监听控制台消息
-	page.On("console", func(message playwright.ConsoleMessage) {
-		log.Printf("console: %s", message.Text())
-	})
-
-	pe.addPage(id, page)
-
-	err = pe.PageVisit(id, url)
-	if err != nil {
-		return nil, err
-	}
-	return page, nil
+	pe.addPage(id)
+	return tabPage.Page(), nil
 }
 
 func (pe *PlaywrightEdge) GetPage(id string) playwright.Page {
-	if page, ok := pe.allPages[id]; ok {
-		return page
+	if tabPage := pe.browser.FindTabPage(id); tabPage != nil {
+		return tabPage.Page()
 	}
 	return nil
 }
 
+func (pe *PlaywrightEdge) CurrentTab() browser.TabPage {
+	return pe.browser.FindTabPage(pe.tabIds[pe.index])
+}
+
 func (pe *PlaywrightEdge) CurrentPage() playwright.Page {
-	return pe.allPages[pe.tabIds[pe.index]]
+	return pe.CurrentTab().Page()
 }
 
 func (pe *PlaywrightEdge) ClosePage(id string) error {
@@ -387,30 +171,14 @@ func (pe *PlaywrightEdge) CloseCurrentPage() error {
 }
 
 func (pe *PlaywrightEdge) PageVisit(id string, url string) error {
-	// 导航
-	page := pe.GetPage(id)
-	if page == nil {
+	tabPage := pe.browser.FindTabPage(id)
+	if tabPage == nil {
 		return fmt.Errorf("未找到 ID 为 %s 的页面", id)
 	}
-	_, err := page.Goto(url, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-	})
-	if err != nil {
-		return fmt.Errorf("无法访问网站: %v", err)
-	}
-
-	block_detector(page, pe.port)
-
-	// 等待页面完全加载
-	err = page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State: playwright.LoadStateLoad,
-	})
-	if err != nil {
-		return fmt.Errorf("等待页面加载失败: %v", err)
+	if err := pe.Pace(url); err != nil {
+		return err
 	}
-	log.Printf("已成功访问网站: %s", url)
-
-	return nil
+	return tabPage.Goto(url)
 }
 
 func (pe *PlaywrightEdge) Visit(url string) error {
@@ -418,43 +186,15 @@ func (pe *PlaywrightEdge) Visit(url string) error {
 }
 
 func (pe *PlaywrightEdge) OpenNewPage(id string, action func() error, timeout float64) (playwright.Page, error) {
-	// 创建一个通道来接收新页面
-	newPageChan := make(chan playwright.Page, 1)
-
-	// 在 goroutine 中监听新页面
-	go func() {
-		newPage, err := pe.context.WaitForEvent("page")
-		if err != nil {
-			log.Printf("等待新页面失败: %v", err)
-			return
-		}
-		newPageObj := newPage.(playwright.Page)
-		err = newPageObj.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-			State: playwright.LoadStateDomcontentloaded,
-		})
-		if err != nil {
-			log.Printf("新页面加载失败: %v", err)
-			return
-		}
-		block_detector(newPageObj, pe.port)
-		newPageChan <- newPageObj
-	}()
-
-	// 触发事件
-	err := action()
-	if err != nil {
-		return nil, fmt.Errorf("触发事件失败: %v", err)
+	if err := pe.Pace(pe.CurrentPage().URL()); err != nil {
+		return nil, err
 	}
-
-	// 等待新页面
-	select {
-	case newPage := <-newPageChan:
-		pe.addPage(id, newPage)
-		log.Printf("成功捕获新标签页，ID: %s", id)
-		return newPage, nil
-	case <-time.After(time.Duration(timeout) * time.Millisecond):
+	tabPage := pe.CurrentTab().OpenInNewTab(id, action, timeout)
+	if tabPage == nil {
 		return nil, fmt.Errorf("超时，未捕获到新标签页")
 	}
+	pe.addPage(id)
+	return tabPage.Page(), nil
 }
 
 func (pe *PlaywrightEdge) SwitchToPage(id string) error {
@@ -470,7 +210,7 @@ func (pe *PlaywrightEdge) SwitchToNextPage() error {
 		return nil
 	}
 	pe.index++
-	pe.allPages[pe.tabIds[pe.index]].BringToFront()
+	pe.browser.SwitchToTabPage(pe.tabIds[pe.index])
 	log.Printf("切换到标签页: %s", pe.tabIds[pe.index])
 	return nil
 }
@@ -480,13 +220,50 @@ func (pe *PlaywrightEdge) SwitchToPreviousPage() error {
 		return nil
 	}
 	pe.index--
-	pe.allPages[pe.tabIds[pe.index]].BringToFront()
+	pe.browser.SwitchToTabPage(pe.tabIds[pe.index])
 	log.Printf("切换到标签页: %s", pe.tabIds[pe.index])
 	return nil
 }
 
+// Pace 按 target（URL 或主机名）所在站点的访问节奏等待，导航、点击和打开详情页前调用
+func (pe *PlaywrightEdge) Pace(target string) error {
+	return siteScheduler.Wait(context.Background(), target)
+}
+
+// Click 按当前站点的访问节奏等待后点击
+func (pe *PlaywrightEdge) Click(locator playwright.Locator) error {
+	if err := pe.Pace(pe.CurrentPage().URL()); err != nil {
+		return err
+	}
+	return locator.Click()
+}
+
+// Press 按当前站点的访问节奏等待后按键，用于回车搜索、跳页等会发出请求的按键
+func (pe *PlaywrightEdge) Press(locator playwright.Locator, key string) error {
+	if err := pe.Pace(pe.CurrentPage().URL()); err != nil {
+		return err
+	}
+	return locator.Press(key)
+}
+
 func (pe *PlaywrightEdge) WaitForSelector(selector string, timeout float64) (playwright.Locator, error) {
-	page := pe.CurrentPage()
+	return wait_for_selector(pe.CurrentPage(), selector, timeout)
+}
+
+// WaitForIdle 等待当前页面的网络请求空闲，用于点击查询等没有可等待元素的操作之后。
+// 有轮询请求的页面可能一直不空闲，最多等待 timeout，超时只记录日志
+func (pe *PlaywrightEdge) WaitForIdle(timeout time.Duration) {
+	err := pe.CurrentPage().WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State:   playwright.LoadStateNetworkidle,
+		Timeout: playwright.Float(float64(timeout.Milliseconds())),
+	})
+	if err != nil {
+		log.Printf("等待页面请求完成超时，继续执行: %v", err)
+	}
+}
+
+// wait_for_selector 在指定页面中等待元素可见，用于不在当前标签页中的详情页
+func wait_for_selector(page playwright.Page, selector string, timeout float64) (playwright.Locator, error) {
 	locator := page.Locator(selector)
 	err := locator.WaitFor(playwright.LocatorWaitForOptions{
 		State:   playwright.WaitForSelectorStateVisible,
@@ -498,37 +275,19 @@ func (pe *PlaywrightEdge) WaitForSelector(selector string, timeout float64) (pla
 	return locator, nil
 }
 
-func (pe *PlaywrightEdge) ClearLocalData() error {
-	// ---------- 清除所有存储 ----------
-	page := pe.CurrentPage()
-	log.Printf("正在清除站点%s的所有本地存储...", page.URL())
-	// 1. 清除 localStorage 和 sessionStorage
-	if _, err := page.Evaluate("localStorage.clear()"); err != nil {
-		log.Fatalf("清空 localStorage 失败: %v", err)
-	}
-	if _, err := page.Evaluate("sessionStorage.clear()"); err != nil {
-		log.Fatalf("清空 sessionStorage 失败: %v", err)
-	}
-
-	// 2. 清除 Cookies（针对当前域名）
-	if err := pe.context.ClearCookies(); err != nil {
-		log.Fatalf("清除 Cookies 失败: %v", err)
+// SaveSession 立即保存会话，下次启动时恢复；浏览器不支持保存会话时忽略
+func (pe *PlaywrightEdge) SaveSession() error {
+	if saver, ok := pe.browser.(interface{ SaveSession() error }); ok {
+		return saver.SaveSession()
 	}
+	return nil
+}
 
-	// 3. 清除 IndexedDB（通过 JavaScript）
-	if _, err := page.Evaluate(`
-		async () => {
-			const databases = await window.indexedDB.databases();
-			for (const db of databases) {
-				if (db.name) {
-					window.indexedDB.deleteDatabase(db.name);
-				}
-			}
-		}
-	`); err != nil {
-		log.Fatalf("清除 IndexedDB 失败: %v", err)
+func (pe *PlaywrightEdge) ClearLocalData() error {
+	err := pe.CurrentTab().ClearLocalData()
+	if err != nil {
+		return err
 	}
 	log.Println("所有存储已清除！")
-
 	return nil
 }
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"rpa-yjj-api/browser"
+
+	"github.com/sssxyd/go-lts-core"
+	"github.com/sssxyd/go-lts-core/rdbms"
+)
+
+// 断点与本地存储共用 data/storage.db
+const checkpoint_datasource_id = "_local_storage"
+
+var checkpoint_statements = []string{
+	`CREATE TABLE IF NOT EXISTS "checkpoint_page" (
+		"id"	INTEGER NOT NULL UNIQUE,
+		"run_id"	TEXT NOT NULL DEFAULT "",
+		"dataset"	TEXT NOT NULL DEFAULT "",
+		"page_no"	INTEGER NOT NULL DEFAULT 0,
+		"done_at"	INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY("id" AUTOINCREMENT)
+	);`,
+	`CREATE INDEX IF NOT EXISTS "idx_checkpoint_page_run" ON "checkpoint_page" (
+		"run_id"	ASC,
+		"dataset"	ASC
+	);`,
+	`CREATE TABLE IF NOT EXISTS "checkpoint_record" (
+		"id"	INTEGER NOT NULL UNIQUE,
+		"run_id"	TEXT NOT NULL DEFAULT "",
+		"dataset"	TEXT NOT NULL DEFAULT "",
+		"page_no"	INTEGER NOT NULL DEFAULT 0,
+		"row_no"	INTEGER NOT NULL DEFAULT 0,
+		"record_key"	TEXT NOT NULL DEFAULT "",
+		"record_value"	TEXT NOT NULL DEFAULT "",
+		"created_at"	INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY("id" AUTOINCREMENT)
+	);`,
+	`CREATE INDEX IF NOT EXISTS "idx_checkpoint_record_run" ON "checkpoint_record" (
+		"run_id"	ASC,
+		"dataset"	ASC
+	);`,
+}
+
+// CheckpointPage 已完成的列表页
+type CheckpointPage struct {
+	ID      int64  `db:"id"`
+	RunID   string `db:"run_id"`
+	Dataset string `db:"dataset"`
+	PageNo  int    `db:"page_no"`
+	DoneAt  int64  `db:"done_at"`
+}
+
+func (p *CheckpointPage) TableName() string {
+	return "checkpoint_page"
+}
+
+func (p *CheckpointPage) PrimaryInt64Key() string {
+	return "id"
+}
+
+func (p *CheckpointPage) DeleteInt64Key() string {
+	return ""
+}
+
+func (p *CheckpointPage) AutoUpdateKeys() []string {
+	return []string{}
+}
+
+// CheckpointRecord 已采集的详情记录，RecordKey 为列表中的注册证号，RecordValue 为记录的 JSON
+type CheckpointRecord struct {
+	ID          int64  `db:"id"`
+	RunID       string `db:"run_id"`
+	Dataset     string `db:"dataset"`
+	PageNo      int    `db:"page_no"`
+	RowNo       int    `db:"row_no"`
+	RecordKey   string `db:"record_key"`
+	RecordValue string `db:"record_value"`
+	CreatedAt   int64  `db:"created_at"`
+}
+
+func (r *CheckpointRecord) TableName() string {
+	return "checkpoint_record"
+}
+
+func (r *CheckpointRecord) PrimaryInt64Key() string {
+	return "id"
+}
+
+func (r *CheckpointRecord) DeleteInt64Key() string {
+	return ""
+}
+
+func (r *CheckpointRecord) AutoUpdateKeys() []string {
+	return []string{}
+}
+
+// Checkpoint 记录一次采集（运行 ID + 数据集）中已完成的列表页和详情行，
+// 每条记录采集后立即写入 storage.db，进程中途退出后用同一个运行 ID 重新启动即可从断点继续
+type Checkpoint struct {
+	runID     string
+	dataset   string
+	dao       rdbms.IDao
+	donePages map[int]bool
+	doneRows  map[string]bool
+	locker    sync.Mutex
+}
+
+// OpenCheckpoint 打开（或新建）断点，run_id 为空时生成新的运行 ID
+func OpenCheckpoint(run_id string, dataset string) (*Checkpoint, error) {
+	if run_id == "" {
+		run_id = browser.NewRunID()
+	}
+	ds := rdbms.GetDataSource(checkpoint_datasource_id)
+	if ds == nil {
+		return nil, fmt.Errorf("本地存储未初始化")
+	}
+	dao := lts.NewDao(checkpoint_datasource_id)
+	for _, statement := range checkpoint_statements {
+		if err := dao.Create(statement); err != nil {
+			return nil, fmt.Errorf("无法创建断点表: %v", err)
+		}
+	}
+	ds.ScanTable(&CheckpointPage{}, &CheckpointRecord{})
+
+	c := &Checkpoint{
+		runID:     run_id,
+		dataset:   dataset,
+		dao:       dao,
+		donePages: make(map[int]bool),
+		doneRows:  make(map[string]bool),
+	}
+
+	pages := []CheckpointPage{}
+	err := dao.Conn().Select(&pages, "SELECT * FROM checkpoint_page WHERE run_id = ? AND dataset = ?", run_id, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取断点: %v", err)
+	}
+	for _, page := range pages {
+		c.donePages[page.PageNo] = true
+	}
+
+	keys := []string{}
+	err = dao.Conn().Select(&keys, "SELECT record_key FROM checkpoint_record WHERE run_id = ? AND dataset = ?", run_id, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取断点: %v", err)
+	}
+	for _, key := range keys {
+		c.doneRows[key] = true
+	}
+
+	if len(pages) > 0 || len(keys) > 0 {
+		log.Printf("从断点继续采集 %s，运行 ID: %s，已完成 %d 页 %d 条", dataset, run_id, len(pages), len(keys))
+	} else {
+		log.Printf("开始采集 %s，运行 ID: %s（中断后可用该 ID 继续）", dataset, run_id)
+	}
+	return c, nil
+}
+
+func (c *Checkpoint) RunID() string {
+	return c.runID
+}
+
+// PageDone 列表页是否已全部采集完毕
+func (c *Checkpoint) PageDone(page_no int) bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.donePages[page_no]
+}
+
+// RowDone 注册证号对应的详情是否已采集
+func (c *Checkpoint) RowDone(key string) bool {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.doneRows[key]
+}
+
+// ResumePage 返回 [start_page, end_page] 中第一个未完成的页码，全部完成时返回 end_page + 1
+func (c *Checkpoint) ResumePage(start_page int, end_page int) int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	page := start_page
+	for page <= end_page && c.donePages[page] {
+		page++
+	}
+	return page
+}
+
+// SaveRow 保存一条详情记录
+func (c *Checkpoint) SaveRow(page_no int, row_no int, key string, record any) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("无法序列化记录 %s: %v", key, err)
+	}
+	_, err = c.dao.TableInsert(&CheckpointRecord{
+		RunID:       c.runID,
+		Dataset:     c.dataset,
+		PageNo:      page_no,
+		RowNo:       row_no,
+		RecordKey:   key,
+		RecordValue: string(value),
+		CreatedAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("无法保存记录 %s: %v", key, err)
+	}
+	c.locker.Lock()
+	c.doneRows[key] = true
+	c.locker.Unlock()
+	return nil
+}
+
+// CompletePage 标记列表页已全部采集完毕
+func (c *Checkpoint) CompletePage(page_no int) error {
+	_, err := c.dao.TableInsert(&CheckpointPage{
+		RunID:   c.runID,
+		Dataset: c.dataset,
+		PageNo:  page_no,
+		DoneAt:  time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("无法保存第 %d 页的断点: %v", page_no, err)
+	}
+	c.locker.Lock()
+	c.donePages[page_no] = true
+	c.locker.Unlock()
+	return nil
+}
+
+// EachValue 按页码和行号顺序逐条读取断点中记录的 JSON，同一注册证号只读取最早的一条
+func (c *Checkpoint) EachValue(fn func(key string, value string) error) (int, error) {
+	rows, err := c.dao.Conn().Queryx("SELECT * FROM checkpoint_record WHERE run_id = ? AND dataset = ? ORDER BY page_no, row_no, id", c.runID, c.dataset)
+	if err != nil {
+		return 0, fmt.Errorf("无法读取断点记录: %v", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	count := 0
+	for rows.Next() {
+		var row CheckpointRecord
+		if err := rows.StructScan(&row); err != nil {
+			return count, fmt.Errorf("无法读取断点记录: %v", err)
+		}
+		if seen[row.RecordKey] {
+			continue
+		}
+		seen[row.RecordKey] = true
+		if err := fn(row.RecordKey, row.RecordValue); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// Finish 采集全部完成后删除本次运行的断点，运行 ID 不能再用于续采
+func (c *Checkpoint) Finish() error {
+	for _, table := range []string{"checkpoint_page", "checkpoint_record"} {
+		_, err := c.dao.Conn().Exec("DELETE FROM "+table+" WHERE run_id = ? AND dataset = ?", c.runID, c.dataset)
+		if err != nil {
+			return fmt.Errorf("无法删除运行 %s 的断点: %v", c.runID, err)
+		}
+	}
+	c.locker.Lock()
+	c.donePages = make(map[int]bool)
+	c.doneRows = make(map[string]bool)
+	c.locker.Unlock()
+	log.Printf("%s 采集完成，已删除运行 %s 的断点", c.dataset, c.runID)
+	return nil
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"rpa-yjj-api/browser"
+	"rpa-yjj-api/retry"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Collector 一个数据集的采集适配器，只负责站点相关的部分：搜索、翻页、读取列表行和解析详情，
+// 分页、重试、断点和输出由 RunCollector 统一处理
+type Collector interface {
+	// Dataset 数据集名称，同时用作断点的数据集
+	Dataset() string
+	// Headers 输出表格的表头
+	Headers() []string
+	// NewRecord 返回一条空记录（指针），用于从断点恢复
+	NewRecord() Record
+	// Search 打开列表并搜索，返回总页数，搜索完成后停留在第 1 页
+	Search(edge *PlaywrightEdge) (int, error)
+	// GoToPage 从第 from 页跳转到第 to 页
+	GoToPage(edge *PlaywrightEdge, from int, to int) error
+	// ListRows 读取当前页的列表行，没有键的行不返回
+	ListRows(edge *PlaywrightEdge, page_no int) ([]CollectorRow, error)
+	// FetchDetail 采集一行的详情，返回前需回到列表页
+	FetchDetail(edge *PlaywrightEdge, row CollectorRow) (Record, error)
+}
+
+// DetailTabCollector 可在独立标签页中解析详情的采集器，CollectOptions.Concurrency 大于 1 时
+// 一页中各行的详情在多个标签页中并发采集
+type DetailTabCollector interface {
+	Collector
+	// OpenDetail 返回在列表页上打开一行详情页的动作，以及等待详情页打开的超时（毫秒）
+	OpenDetail(row CollectorRow) (func() error, float64)
+	// ParseDetail 解析已打开的详情页 page，page 不一定是 edge 的当前标签页
+	ParseDetail(edge *PlaywrightEdge, page playwright.Page) (Record, error)
+}
+
+// CollectorRow 列表中的一行
+type CollectorRow struct {
+	Index  int    // 行号，从 1 开始
+	Key    string // 用于去重和断点的键，通常为注册证号
+	Handle any    // 适配器自己的行数据，例如详情按钮的 Locator
+}
+
+// CollectOptions 采集参数
+type CollectOptions struct {
+	OutputPath    string
+	StartPage     int
+	EndPage       int           // 为 0 或超过总页数时采集到最后一页
+	RunID         string        // 断点续采的运行 ID，为空时开始新的采集
+	Retries       int           // 详情和翻页失败后的重试次数，默认为 2
+	RetryDelay    time.Duration // 重试前的等待时间，默认为 3 秒
+	FlushInterval time.Duration // 输出定时落盘的间隔，默认为 30 秒
+	Concurrency   int           // 同时打开的详情页数量，大于 1 且采集器实现 DetailTabCollector 时并发采集，默认为 1
+}
+
+// detailConcurrency CollectDataset 和 CollectWith 同时打开的详情页数量，可在启动采集前由命令行参数修改
+var detailConcurrency = 1
+
+var collector_registry = map[string]func() Collector{}
+
+// RegisterCollector 注册数据集的采集适配器，通常在适配器所在文件的 init 中调用
+func RegisterCollector(dataset string, factory func() Collector) {
+	if _, ok := collector_registry[dataset]; ok {
+		panic(fmt.Sprintf("数据集 %s 重复注册", dataset))
+	}
+	collector_registry[dataset] = factory
+}
+
+// OverrideCollector 注册或替换数据集的采集适配器，用于数据集定义文件覆盖已编译的适配器
+func OverrideCollector(dataset string, factory func() Collector) {
+	collector_registry[dataset] = factory
+}
+
+// NewCollector 创建已注册数据集的采集适配器
+func NewCollector(dataset string) (Collector, error) {
+	factory, ok := collector_registry[dataset]
+	if !ok {
+		return nil, fmt.Errorf("未知的数据集 %s，可选: %s", dataset, strings.Join(CollectorDatasets(), ", "))
+	}
+	return factory(), nil
+}
+
+// CollectorDatasets 已注册的数据集名称
+func CollectorDatasets() []string {
+	names := make([]string, 0, len(collector_registry))
+	for name := range collector_registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CollectDataset 采集已注册的数据集，失败时退出进程
+func CollectDataset(dataset string, output_path string, start_page int, end_page int, run_id string) {
+	collector, err := NewCollector(dataset)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	CollectWith(collector, output_path, start_page, end_page, run_id)
+}
+
+// CollectWith 使用指定的采集器采集，用于带查询条件等注册表无法表达的参数，失败时退出进程
+func CollectWith(collector Collector, output_path string, start_page int, end_page int, run_id string) {
+	err := RunCollector(collector, &CollectOptions{
+		OutputPath:  output_path,
+		StartPage:   start_page,
+		EndPage:     end_page,
+		RunID:       run_id,
+		Concurrency: detailConcurrency,
+	})
+	if err != nil {
+		log.Fatalf("采集 %s 失败: %v", collector.Dataset(), err)
+	}
+}
+
+// RunCollector 启动浏览器，按页采集数据集：已完成的页和已采集的行从断点中跳过，
+// 每条记录采集后立即写入断点和输出
+func RunCollector(collector Collector, options *CollectOptions) error {
+	opts := *options
+	if opts.Retries <= 0 {
+		opts.Retries = 2
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 3 * time.Second
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 30 * time.Second
+	}
+	if opts.StartPage <= 0 {
+		opts.StartPage = 1
+	}
+
+	checkpoint, err := OpenCheckpoint(opts.RunID, collector.Dataset())
+	if err != nil {
+		return fmt.Errorf("无法打开断点: %v", err)
+	}
+
+	sink, err := NewRecordSink(opts.OutputPath, collector.Headers(), opts.FlushInterval)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+
+	// 先写入断点中已采集的记录
+	restored, err := checkpoint.EachValue(func(key string, value string) error {
+		record := collector.NewRecord()
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return fmt.Errorf("无法解析记录 %s: %v", key, err)
+		}
+		return sink.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	if restored > 0 {
+		log.Printf("已从断点恢复 %d 条数据", restored)
+	}
+
+	edge, err := newCollectorEdge()
+	if err != nil {
+		return fmt.Errorf("无法启动 Edge 浏览器: %v", err)
+	}
+	defer edge.Close()
+
+	driver := &collectorDriver{collector: collector, options: &opts, edge: edge, checkpoint: checkpoint, sink: sink}
+	err = driver.run()
+	log_retry_stats()
+	if err != nil {
+		return err
+	}
+
+	if err = sink.Close(); err != nil {
+		return err
+	}
+	// 有失败的行时保留断点，用同一个运行 ID 重新启动只采集失败的行
+	if driver.failed > 0 {
+		log.Printf("共 %d 条数据采集失败，可用运行 ID %s 重新采集", driver.failed, checkpoint.RunID())
+	} else if err = checkpoint.Finish(); err != nil {
+		log.Printf("%v", err)
+	}
+	if err = edge.ClearLocalData(); err != nil {
+		log.Printf("清除存储失败: %v", err)
+	}
+	return nil
+}
+
+// newCollectorEdge 启动采集使用的浏览器，测试中替换为回放 HAR 夹具的假浏览器
+var newCollectorEdge = func() (*PlaywrightEdge, error) {
+	return NewPlaywrightEdge(0)
+}
+
+type collectorDriver struct {
+	collector  Collector
+	options    *CollectOptions
+	edge       *PlaywrightEdge
+	checkpoint *Checkpoint
+	sink       RecordSink
+	failed     int // 重试后仍失败、未写入断点的行数
+}
+
+func (d *collectorDriver) run() error {
+	dataset := d.collector.Dataset()
+	pageCount, err := d.collector.Search(d.edge)
+	if err != nil {
+		d.edge.Diagnose(fmt.Sprintf("搜索 %s 失败", dataset), err)
+		return fmt.Errorf("搜索失败: %v", err)
+	}
+	log.Printf("%s 共 %d 页", dataset, pageCount)
+	if pageCount == 0 {
+		return nil
+	}
+	end_page := d.options.EndPage
+	if end_page <= 0 || end_page > pageCount {
+		end_page = pageCount
+	}
+
+	// 跳过断点中已完成的页
+	start_page := d.checkpoint.ResumePage(d.options.StartPage, end_page)
+	if start_page > end_page {
+		log.Printf("第 %d 至 %d 页均已采集", d.options.StartPage, end_page)
+		return nil
+	}
+	if start_page > 1 {
+		if err = d.goToPage(1, start_page); err != nil {
+			return err
+		}
+	}
+
+	for i := start_page; i <= end_page; i++ {
+		if d.checkpoint.PageDone(i) {
+			log.Printf("第 %d 页已采集，跳过", i)
+		} else {
+			log.Printf("正在获取第 %d 页数据", i)
+			count, failed, err := d.collectPage(i)
+			if err != nil {
+				d.edge.Diagnose(fmt.Sprintf("获取第 %d 页 %s 失败", i, dataset), err)
+				return fmt.Errorf("获取第 %d 页数据失败: %v", i, err)
+			}
+			// 有失败的行时不标记该页完成，续采时回到该页重试失败的行
+			if failed > 0 {
+				d.failed += failed
+				log.Printf("第 %d 页新增 %d 条，%d 条失败", i, count, failed)
+			} else {
+				if err = d.checkpoint.CompletePage(i); err != nil {
+					return err
+				}
+				log.Printf("第 %d 页数据获取完毕，新增 %d 条", i, count)
+			}
+		}
+		if i == end_page {
+			log.Println("已到达最后一页")
+			break
+		}
+		if err = d.goToPage(i, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *collectorDriver) goToPage(from int, to int) error {
+	err := retry.Do(context.Background(), collectPolicy("翻页", d.options), func(ctx context.Context, attempt int) error {
+		return d.collector.GoToPage(d.edge, from, to)
+	})
+	if err == nil {
+		return nil
+	}
+	if retryable(err) {
+		d.edge.Diagnose(fmt.Sprintf("跳转到第 %d 页失败", to), err)
+	}
+	return fmt.Errorf("跳转到第 %d 页失败: %w", to, err)
+}
+
+// collectPage 采集一页中未采集的行，返回新增和失败的行数；重试后仍失败的行不写入断点和输出，
+// 站点当天的操作次数用完等不可重试的错误直接返回
+func (d *collectorDriver) collectPage(page_no int) (int, int, error) {
+	rows, err := d.listRows(page_no)
+	if err != nil {
+		return 0, 0, err
+	}
+	log.Printf("第%d页共 %d 条数据", page_no, len(rows))
+
+	pending := make([]CollectorRow, 0, len(rows))
+	for _, row := range rows {
+		if d.checkpoint.RowDone(row.Key) {
+			log.Printf("第 %d 页第 %d 条数据 %s 已采集，跳过", page_no, row.Index, row.Key)
+			continue
+		}
+		pending = append(pending, row)
+	}
+
+	// 并发采集的结果与 pending 顺序一致，失败的行为 nil，在下面逐行重试
+	records := make([]Record, len(pending))
+	if c, ok := d.collector.(DetailTabCollector); ok && d.options.Concurrency > 1 && len(pending) > 1 {
+		records = d.fetchDetailsInTabs(c, page_no, pending)
+	}
+
+	count, failed := 0, 0
+	for i, row := range pending {
+		record := records[i]
+		if record == nil {
+			log.Printf("正在获取第 %d 页第 %d 条数据", page_no, row.Index)
+			record, err = d.fetchDetail(row)
+			if err != nil {
+				if !retryable(err) {
+					return count, failed, err
+				}
+				log.Printf("第 %d 页第 %d 条数据 %s 采集失败，续采时重试: %v", page_no, row.Index, row.Key, err)
+				failed++
+				continue
+			}
+		}
+		if err = d.checkpoint.SaveRow(page_no, row.Index, row.Key, record); err != nil {
+			return count, failed, err
+		}
+		if err = d.sink.Write(record); err != nil {
+			return count, failed, err
+		}
+		count++
+	}
+	return count, failed, nil
+}
+
+func (d *collectorDriver) fetchDetail(row CollectorRow) (Record, error) {
+	return retry.DoValue(context.Background(), collectPolicy("采集详情", d.options), func(ctx context.Context, attempt int) (Record, error) {
+		return d.collector.FetchDetail(d.edge, row)
+	})
+}
+
+func (d *collectorDriver) listRows(page_no int) ([]CollectorRow, error) {
+	return retry.DoValue(context.Background(), &listRowsPolicy, func(ctx context.Context, attempt int) ([]CollectorRow, error) {
+		return d.collector.ListRows(d.edge, page_no)
+	})
+}
+
+// fetchDetailsInTabs 在多个详情标签页中并发采集 rows，返回与 rows 顺序一致的记录，失败的行为 nil
+func (d *collectorDriver) fetchDetailsInTabs(c DetailTabCollector, page_no int, rows []CollectorRow) []Record {
+	tasks := make([]browser.TabTask[Record], len(rows))
+	for i, row := range rows {
+		open, timeout := c.OpenDetail(row)
+		tasks[i] = browser.TabTask[Record]{
+			Open:    open,
+			Timeout: time.Duration(timeout) * time.Millisecond,
+			Parse: func(ctx context.Context, tab browser.TabPageV2) (Record, error) {
+				return c.ParseDetail(d.edge, tab.Page())
+			},
+		}
+	}
+
+	log.Printf("正在用 %d 个标签页并发获取第 %d 页的 %d 条数据", d.options.Concurrency, page_no, len(rows))
+	results := browser.FetchInTabs(context.Background(), d.edge.browser, d.edge.CurrentTab(), tasks, &browser.TabPoolOptions{
+		Concurrency: d.options.Concurrency,
+		Scheduler:   siteScheduler,
+	})
+	records := make([]Record, len(rows))
+	for i, result := range results {
+		if result.Err != nil {
+			log.Printf("第 %d 页第 %d 条数据 %s 并发采集失败，稍后重试: %v", page_no, rows[i].Index, rows[i].Key, result.Err)
+			continue
+		}
+		records[i] = result.Value
+	}
+	return records
+}
+
+// collect_detail_tab 在当前标签页上打开详情页并解析，DetailTabCollector 的 FetchDetail 通常直接调用
+func collect_detail_tab(edge *PlaywrightEdge, c DetailTabCollector, row CollectorRow) (Record, error) {
+	open, timeout := c.OpenDetail(row)
+	return collect_detail_page(edge, open, timeout, func() (Record, error) {
+		return c.ParseDetail(edge, edge.CurrentPage())
+	})
+}
+
+// collect_detail_page 点击打开详情页并解析，无论成功与否都会回到列表页并关闭详情页
+func collect_detail_page(edge *PlaywrightEdge, open func() error, timeout float64, parse func() (Record, error)) (Record, error) {
+	_, err := edge.OpenNewPage("详情页", open, timeout)
+	if err != nil {
+		return nil, err
+	}
+	// 切换到详情页
+	edge.SwitchToNextPage()
+	defer func() {
+		// 返回列表页
+		edge.SwitchToPreviousPage()
+		// 关闭详情页
+		edge.ClosePage("详情页")
+	}()
+	return parse()
+}
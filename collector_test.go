@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+const nmpaHomeURL = "https://www.nmpa.gov.cn/datasearch/home-index.html"
+
+// replayImportDrugSite 从 HAR 夹具加载境外生产药品的页面，并按真实页面的行为脚本化：
+// 回车搜索打开列表页，下一页切换到第 2 页，详情按钮打开对应注册证号的详情页
+func replayImportDrugSite(t *testing.T) *fake.Site {
+	t.Helper()
+	site := fake.NewSite()
+	if err := site.LoadHAR(filepath.Join("testdata", "nmpa_import_drugs.har")); err != nil {
+		t.Fatalf("%v", err)
+	}
+	const base = "https://www.nmpa.gov.cn/datasearch/"
+	site.OnPress(nmpaHomeURL, "div.search-input input", "Enter", fake.OpenPopup(base+"search-result.html")).
+		OnClick(base+"search-result.html", "div.el-pagination > button.btn-next", fake.Navigate(base+"search-result.html?page=2"))
+	for url, keys := range map[string][]string{
+		base + "search-result.html":        {"H20200001", "H20200002"},
+		base + "search-result.html?page=2": {"H20200003"},
+	} {
+		for i, key := range keys {
+			selector := fmt.Sprintf("table > tbody > tr:nth-child(%d) button", i+1)
+			site.OnClick(url, selector, fake.OpenPopup(base+"search-info.html?id="+key))
+		}
+	}
+	return site
+}
+
+// 回放 HAR 夹具完整运行境外生产药品采集：搜索、翻页、打开详情页并写入 CSV，结果可重复
+func TestCollectImportDrugsReplay(t *testing.T) {
+	for run := 1; run <= 2; run++ {
+		edge := newFakeBrowserEdge(t, replayImportDrugSite(t))
+		saved := newCollectorEdge
+		newCollectorEdge = func() (*PlaywrightEdge, error) { return edge, nil }
+		t.Cleanup(func() { newCollectorEdge = saved })
+
+		output := filepath.Join(t.TempDir(), "import_drugs.csv")
+		err := RunCollector(NewImportDrugCollector(&DefaultImportDrugQuery), &CollectOptions{
+			OutputPath:  output,
+			StartPage:   1,
+			Concurrency: 1,
+		})
+		if err != nil {
+			t.Fatalf("第 %d 次回放 RunCollector() error = %v", run, err)
+		}
+
+		file, err := os.Open(output)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		rows, err := csv.NewReader(file).ReadAll()
+		file.Close()
+		if err != nil {
+			t.Fatalf("读取输出失败: %v", err)
+		}
+		// CSV 以 UTF-8 BOM 开头，便于 Excel 识别编码
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		if len(rows) != 4 || !slices.Equal(rows[0], GetMedicineDataHeaders()) {
+			t.Fatalf("第 %d 次回放输出 %d 行, 表头 %v", run, len(rows), rows[0])
+		}
+		registerNo := slices.Index(rows[0], "注册证号")
+		productName := slices.Index(rows[0], "产品名称（中文）")
+		want := [][2]string{{"H20200001", "阿司匹林肠溶片"}, {"H20200002", "布洛芬缓释胶囊"}, {"H20200003", "对乙酰氨基酚片"}}
+		for i, row := range rows[1:] {
+			if got := [2]string{row[registerNo], row[productName]}; got != want[i] {
+				t.Errorf("第 %d 次回放第 %d 行 = %v, want %v", run, i+1, got, want[i])
+			}
+		}
+	}
+}
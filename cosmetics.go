@@ -0,0 +1,193 @@
+package main
+
+// CosmeticRegistration 特殊化妆品注册信息
+type CosmeticRegistration struct {
+	Category                 string // 分类，如 国产特殊化妆品
+	ProductName              string // 产品名称
+	ProductNameEN            string // 产品名称（英文）
+	RegisterNo               string // 注册证号/批准文号
+	Registrant               string // 注册人
+	RegistrantAddress        string // 注册人地址
+	ResponsiblePerson        string // 境内责任人
+	ResponsiblePersonAddress string // 境内责任人地址
+	Manufacturer             string // 生产企业
+	ManufacturerAddress      string // 生产企业地址
+	ProductCategory          string // 产品类别
+	ApprovalDate             string // 批准日期
+	ValidUntil               string // 批件有效期
+	Remark                   string // 备注
+}
+
+// CosmeticFiling 普通化妆品备案信息
+type CosmeticFiling struct {
+	Category                 string // 分类，如 国产普通化妆品备案
+	ProductName              string // 产品名称
+	FilingNo                 string // 备案编号
+	FilingPerson             string // 备案人
+	FilingPersonAddress      string // 备案人地址
+	ResponsiblePerson        string // 境内责任人
+	ResponsiblePersonAddress string // 境内责任人地址
+	Manufacturer             string // 生产企业
+	ManufacturerAddress      string // 生产企业地址
+	FilingDate               string // 备案日期
+	Remark                   string // 备注
+}
+
+// cosmeticRegistrationLabels 特殊化妆品注册详情表格的标签
+var cosmeticRegistrationLabels = NewLabelDictionary([]LabelField{
+	{Field: "ProductName", Label: "产品名称", Aliases: []string{"产品中文名称", "产品名称（中文）"}},
+	{Field: "ProductNameEN", Label: "产品名称（英文）", Aliases: []string{"产品英文名称"}},
+	{Field: "RegisterNo", Label: "注册证号", Aliases: []string{"批准文号", "注册证编号"}},
+	{Field: "Registrant", Label: "注册人", Aliases: []string{"注册人名称", "申请人"}},
+	{Field: "RegistrantAddress", Label: "注册人地址", Aliases: []string{"注册人住所"}},
+	{Field: "ResponsiblePerson", Label: "境内责任人", Aliases: []string{"境内责任人名称", "在华申报责任单位"}},
+	{Field: "ResponsiblePersonAddress", Label: "境内责任人地址", Aliases: []string{"在华申报责任单位地址"}},
+	{Field: "Manufacturer", Label: "生产企业", Aliases: []string{"生产企业名称"}},
+	{Field: "ManufacturerAddress", Label: "生产企业地址"},
+	{Field: "ProductCategory", Label: "产品类别"},
+	{Field: "ApprovalDate", Label: "批准日期"},
+	{Field: "ValidUntil", Label: "批件有效期", Aliases: []string{"批准文号有效期", "有效期至"}},
+	{Field: "Remark", Label: "备注"},
+})
+
+// cosmeticFilingLabels 普通化妆品备案详情表格的标签
+var cosmeticFilingLabels = NewLabelDictionary([]LabelField{
+	{Field: "ProductName", Label: "产品名称", Aliases: []string{"产品中文名称", "产品名称（中文）"}},
+	{Field: "FilingNo", Label: "备案编号", Aliases: []string{"备案号"}},
+	{Field: "FilingPerson", Label: "备案人", Aliases: []string{"备案人名称"}},
+	{Field: "FilingPersonAddress", Label: "备案人地址"},
+	{Field: "ResponsiblePerson", Label: "境内责任人", Aliases: []string{"境内责任人名称"}},
+	{Field: "ResponsiblePersonAddress", Label: "境内责任人地址"},
+	{Field: "Manufacturer", Label: "生产企业", Aliases: []string{"生产企业名称"}},
+	{Field: "ManufacturerAddress", Label: "生产企业地址"},
+	{Field: "FilingDate", Label: "备案日期"},
+	{Field: "Remark", Label: "备注"},
+})
+
+func GetCosmeticRegistrationHeaders() []string {
+	return []string{
+		"分类",
+		"产品名称",
+		"产品名称（英文）",
+		"注册证号",
+		"注册人",
+		"注册人地址",
+		"境内责任人",
+		"境内责任人地址",
+		"生产企业",
+		"生产企业地址",
+		"产品类别",
+		"批准日期",
+		"批件有效期",
+		"备注",
+	}
+}
+
+func GetCosmeticFilingHeaders() []string {
+	return []string{
+		"分类",
+		"产品名称",
+		"备案编号",
+		"备案人",
+		"备案人地址",
+		"境内责任人",
+		"境内责任人地址",
+		"生产企业",
+		"生产企业地址",
+		"备案日期",
+		"备注",
+	}
+}
+
+func (cosmetic *CosmeticRegistration) ToRowData() []string {
+	return []string{
+		cosmetic.Category,
+		cosmetic.ProductName,
+		cosmetic.ProductNameEN,
+		cosmetic.RegisterNo,
+		cosmetic.Registrant,
+		cosmetic.RegistrantAddress,
+		cosmetic.ResponsiblePerson,
+		cosmetic.ResponsiblePersonAddress,
+		cosmetic.Manufacturer,
+		cosmetic.ManufacturerAddress,
+		cosmetic.ProductCategory,
+		cosmetic.ApprovalDate,
+		cosmetic.ValidUntil,
+		cosmetic.Remark,
+	}
+}
+
+func (cosmetic *CosmeticFiling) ToRowData() []string {
+	return []string{
+		cosmetic.Category,
+		cosmetic.ProductName,
+		cosmetic.FilingNo,
+		cosmetic.FilingPerson,
+		cosmetic.FilingPersonAddress,
+		cosmetic.ResponsiblePerson,
+		cosmetic.ResponsiblePersonAddress,
+		cosmetic.Manufacturer,
+		cosmetic.ManufacturerAddress,
+		cosmetic.FilingDate,
+		cosmetic.Remark,
+	}
+}
+
+// cosmeticListColumns 化妆品列表：序号、注册证号（备案编号）、产品名称、注册人（备案人）、日期、详情按钮。
+// 与医疗器械列表一样，单元格中没有药品列表的 <p>，详情按钮在最后一列；列的顺序尚未在线上页面核对
+var cosmeticListColumns = nmpaListColumns{
+	key:    "td:nth-child(2)",
+	detail: "td:last-child button",
+}
+
+// 化妆品的注册分类和备案分类：数据集名称 -> 首页分类链接的标题
+var (
+	cosmeticRegistrationCategories = map[string]string{
+		"cosmetics_domestic": "国产特殊化妆品",
+		"cosmetics_import":   "进口特殊化妆品",
+	}
+	cosmeticFilingCategories = map[string]string{
+		"cosmetics_domestic_filing": "国产普通化妆品备案",
+		"cosmetics_import_filing":   "进口普通化妆品备案",
+	}
+)
+
+func init() {
+	for dataset, title := range cosmeticRegistrationCategories {
+		RegisterCollector(dataset, func() Collector {
+			return &nmpaCategoryCollector{
+				dataset:    dataset,
+				category:   "hzp",
+				title:      title,
+				columns:    cosmeticListColumns,
+				headers:    GetCosmeticRegistrationHeaders(),
+				labels:     cosmeticRegistrationLabels,
+				readyField: "RegisterNo",
+				newRecord:  func() Record { return &CosmeticRegistration{Category: title} },
+				summary: func(record Record) string {
+					cosmetic := record.(*CosmeticRegistration)
+					return cosmetic.ProductName + " " + cosmetic.RegisterNo
+				},
+			}
+		})
+	}
+	for dataset, title := range cosmeticFilingCategories {
+		RegisterCollector(dataset, func() Collector {
+			return &nmpaCategoryCollector{
+				dataset:    dataset,
+				category:   "hzp",
+				title:      title,
+				columns:    cosmeticListColumns,
+				headers:    GetCosmeticFilingHeaders(),
+				labels:     cosmeticFilingLabels,
+				readyField: "FilingNo",
+				newRecord:  func() Record { return &CosmeticFiling{Category: title} },
+				summary: func(record Record) string {
+					cosmetic := record.(*CosmeticFiling)
+					return cosmetic.ProductName + " " + cosmetic.FilingNo
+				},
+			}
+		})
+	}
+}
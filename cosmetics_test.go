@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+// cosmeticListPage 化妆品列表页，注册证号（备案编号）在第 2 列，详情按钮在最后一列
+func cosmeticListPage(keys ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div class="el-table"><table><tbody>`)
+	for i, key := range keys {
+		fmt.Fprintf(&sb, `<tr><td><div class="cell">%d</div></td><td><div class="cell"><span>%s</span></div></td><td><div class="cell">某某面霜</div></td>`, i+1, key)
+		fmt.Fprintf(&sb, `<td><div class="cell">某化妆品公司</div></td><td><div class="cell">2021-05-01</div></td><td><div class="cell"><button class="el-button">详情 %s</button></div></td></tr>`, key)
+	}
+	sb.WriteString(`</tbody></table></div></body></html>`)
+	return sb.String()
+}
+
+func TestCosmeticListRows(t *testing.T) {
+	tests := []struct {
+		dataset string
+		keys    []string
+	}{
+		{"cosmetics_domestic", []string{"国妆特字G20210001", "国妆特字G20210002"}},
+		{"cosmetics_import", []string{"国妆特进字J20210001", "国妆特进字J20210002"}},
+		{"cosmetics_domestic_filing", []string{"粤G妆网备字2021000001", "粤G妆网备字2021000002"}},
+		{"cosmetics_import_filing", []string{"国妆网备进字（沪）2021000001", "国妆网备进字（沪）2021000002"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataset, func(t *testing.T) {
+			site := fake.NewSite().Page(nmpaListURL, cosmeticListPage(tt.keys[0], "", tt.keys[1]))
+			edge := newFakeEdge(t, site, nmpaListURL)
+			collector, err := NewCollector(tt.dataset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := collector.ListRows(edge, 1)
+			if err != nil {
+				t.Fatalf("ListRows() error = %v", err)
+			}
+			checkRows(t, rows, tt.keys, []int{1, 3}, "详情 ")
+		})
+	}
+}
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"gopkg.in/yaml.v3"
+)
+
+// DatasetDefinition 用 YAML（或 JSON）描述的数据集：入口页面、搜索步骤、列表和分页选择器、
+// 详情表格中按标签取值的字段以及输出列，由 definedCollector 解释执行，修改选择器和字段无需重新编译
+type DatasetDefinition struct {
+	Name     string            `yaml:"name"`      // 数据集名称
+	Override bool              `yaml:"override"`  // 与已编译的采集器同名时替换已编译的采集器，否则视为错误
+	Title    string            `yaml:"title"`     // 数据集说明，仅用于日志
+	StartURL string            `yaml:"start_url"` // 入口页面
+	Search   []DefinitionStep  `yaml:"search"`    // 打开入口页面后执行的搜索步骤，执行完后应停留在列表第 1 页
+	List     DefinitionList    `yaml:"list"`
+	Detail   DefinitionDetail  `yaml:"detail"`
+	Fields   []DefinitionField `yaml:"fields"`
+
+	path   string           // 定义文件路径
+	labels *LabelDictionary // 由 Fields 生成的标签字典
+}
+
+// DefinitionStep 搜索步骤，Action 可选：
+//
+//	visit        打开 URL
+//	wait         等待页面网络请求空闲，最多等待 Duration
+//	wait_for     等待 Selector 显示
+//	click        点击 Selector
+//	fill         在 Selector 中填入 Value
+//	press        在 Selector 上按下 Key，Selector 为空时在页面上按键
+//	select       在下拉框 Selector 中选择 Value
+//	save_session 保存会话
+type DefinitionStep struct {
+	Action   string        `yaml:"action"`
+	Selector string        `yaml:"selector"`
+	Value    string        `yaml:"value"`
+	Key      string        `yaml:"key"`
+	URL      string        `yaml:"url"`
+	Duration time.Duration `yaml:"duration"`
+	Timeout  time.Duration `yaml:"timeout"`  // 等待 Selector 的超时时间，默认为 3 秒
+	NewTab   string        `yaml:"new_tab"`  // 该步骤会打开新标签页时填写标签页 ID，执行后切换到新标签页
+	Optional bool          `yaml:"optional"` // 失败时只记录日志，继续执行后续步骤
+}
+
+// DefinitionList 列表页
+type DefinitionList struct {
+	PageCount   string `yaml:"page_count"`    // 显示总页数的元素
+	NextPage    string `yaml:"next_page"`     // 下一页按钮
+	ActivePage  string `yaml:"active_page"`   // 分页器中显示当前页码的元素，翻页后等待其变为目标页
+	PageInput   string `yaml:"page_input"`    // 跳转页码的输入框，为空时只能逐页翻页
+	Rows        string `yaml:"rows"`          // 列表行
+	Key         string `yaml:"key"`           // 行内作为断点键的元素，通常为注册证号
+	Detail      string `yaml:"detail"`        // 行内打开详情页的按钮
+	StopAtEmpty bool   `yaml:"stop_at_empty"` // 遇到键为空的行时结束本页，否则跳过该行
+}
+
+// DefinitionDetail 详情页中的两列表格，每行为 标签 | 值
+type DefinitionDetail struct {
+	Timeout      time.Duration `yaml:"timeout"`       // 等待详情页打开的超时时间，默认为 10 秒
+	Table        string        `yaml:"table"`         // 详情表格
+	Label        string        `yaml:"label"`         // 行内的标签单元格，默认为 td:nth-child(1)
+	Value        string        `yaml:"value"`         // 行内的值单元格，默认为 td:nth-child(2)
+	Ready        string        `yaml:"ready"`         // 表格内容加载完成后不为空的元素，为空时表格显示即可
+	ReadyTimeout time.Duration `yaml:"ready_timeout"` // 等待 Ready 的超时时间，默认为 10 秒
+}
+
+// DefinitionField 输出列，值取自详情表格中标签为 Label 或 Aliases 之一的行
+type DefinitionField struct {
+	Column  string   `yaml:"column"`
+	Label   string   `yaml:"label"` // 为空时与 Column 相同
+	Aliases []string `yaml:"aliases"`
+}
+
+// LoadDatasetDefinitions 读取目录中的 .yaml、.yml 和 .json 定义文件，目录不存在时返回空
+func LoadDatasetDefinitions(dir string) ([]*DatasetDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取数据集定义目录: %v", err)
+	}
+
+	definitions := make([]*DatasetDefinition, 0, len(entries))
+	names := make(map[string]string)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		definition, err := LoadDatasetDefinition(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := names[definition.Name]; ok {
+			return nil, fmt.Errorf("数据集 %s 在 %s 和 %s 中重复定义", definition.Name, other, path)
+		}
+		names[definition.Name] = path
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// LoadDatasetDefinition 读取一个定义文件，JSON 按 YAML 解析
+func LoadDatasetDefinition(path string) (*DatasetDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取数据集定义 %s: %v", path, err)
+	}
+	definition := &DatasetDefinition{path: path}
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(definition); err != nil {
+		return nil, fmt.Errorf("无法解析数据集定义 %s: %v", path, err)
+	}
+	if err = definition.validate(); err != nil {
+		return nil, fmt.Errorf("数据集定义 %s 有误: %v", path, err)
+	}
+	return definition, nil
+}
+
+// RegisterDatasetDefinitions 读取目录中的定义并注册为采集器。与已编译的采集器同名的定义需设置 override，
+// 替换后该数据集的查询参数、标签别名等只在已编译的采集器中实现的功能不再生效，
+// 已编译的采集器留下的断点也不能用定义文件续采
+func RegisterDatasetDefinitions(dir string) error {
+	definitions, err := LoadDatasetDefinitions(dir)
+	if err != nil {
+		return err
+	}
+	for _, definition := range definitions {
+		definition := definition
+		if _, ok := collector_registry[definition.Name]; ok {
+			if !definition.Override {
+				return fmt.Errorf("数据集定义 %s 与内置的数据集 %s 同名，如需替换内置采集器请设置 override: true", definition.path, definition.Name)
+			}
+			log.Printf("数据集 %s 的内置采集器已被定义文件 %s 替换", definition.Name, definition.path)
+		} else {
+			log.Printf("数据集 %s 使用定义文件 %s", definition.Name, definition.path)
+		}
+		OverrideCollector(definition.Name, func() Collector { return newDefinedCollector(definition) })
+	}
+	return nil
+}
+
+func (d *DatasetDefinition) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("缺少 name")
+	}
+	if d.StartURL == "" {
+		return fmt.Errorf("缺少 start_url")
+	}
+	if d.List.PageCount == "" || d.List.ActivePage == "" || d.List.Rows == "" || d.List.Key == "" || d.List.Detail == "" {
+		return fmt.Errorf("list 中 page_count、active_page、rows、key、detail 均不能为空")
+	}
+	if d.List.NextPage == "" && d.List.PageInput == "" {
+		return fmt.Errorf("list 中 next_page 和 page_input 至少填写一个")
+	}
+	if d.Detail.Table == "" {
+		return fmt.Errorf("缺少 detail.table")
+	}
+	if d.Detail.Label == "" {
+		d.Detail.Label = "td:nth-child(1)"
+	}
+	if d.Detail.Value == "" {
+		d.Detail.Value = "td:nth-child(2)"
+	}
+	if d.Detail.Timeout <= 0 {
+		d.Detail.Timeout = 10 * time.Second
+	}
+	if d.Detail.ReadyTimeout <= 0 {
+		d.Detail.ReadyTimeout = 10 * time.Second
+	}
+
+	for i, step := range d.Search {
+		switch step.Action {
+		case "visit":
+			if step.URL == "" {
+				return fmt.Errorf("第 %d 个搜索步骤 visit 缺少 url", i+1)
+			}
+		case "wait":
+			if step.Duration <= 0 {
+				return fmt.Errorf("第 %d 个搜索步骤 wait 缺少 duration", i+1)
+			}
+		case "wait_for", "click", "fill", "select":
+			if step.Selector == "" {
+				return fmt.Errorf("第 %d 个搜索步骤 %s 缺少 selector", i+1, step.Action)
+			}
+		case "press":
+			if step.Key == "" {
+				return fmt.Errorf("第 %d 个搜索步骤 press 缺少 key", i+1)
+			}
+		case "save_session":
+		default:
+			return fmt.Errorf("第 %d 个搜索步骤的 action %q 无效", i+1, step.Action)
+		}
+	}
+
+	if len(d.Fields) == 0 {
+		return fmt.Errorf("缺少 fields")
+	}
+	columns := make(map[string]bool)
+	labelFields := make([]LabelField, 0, len(d.Fields))
+	for i := range d.Fields {
+		field := &d.Fields[i]
+		if field.Column == "" {
+			return fmt.Errorf("第 %d 个字段缺少 column", i+1)
+		}
+		if columns[field.Column] {
+			return fmt.Errorf("输出列 %s 重复", field.Column)
+		}
+		columns[field.Column] = true
+		if field.Label == "" {
+			field.Label = field.Column
+		}
+		labelFields = append(labelFields, LabelField{Field: field.Column, Label: field.Label, Aliases: field.Aliases})
+	}
+	labels, err := newLabelDictionary(labelFields)
+	if err != nil {
+		return err
+	}
+	d.labels = labels
+	return nil
+}
+
+// Columns 输出列
+func (d *DatasetDefinition) Columns() []string {
+	columns := make([]string, 0, len(d.Fields))
+	for _, field := range d.Fields {
+		columns = append(columns, field.Column)
+	}
+	return columns
+}
+
+// normalizeLabel 去掉标签两端的空白和冒号，统一全角和半角括号
+func normalizeLabel(label string) string {
+	label = strings.TrimSpace(label)
+	label = strings.TrimRight(label, ":： ")
+	label = strings.NewReplacer("(", "（", ")", "）").Replace(label)
+	return strings.TrimSpace(label)
+}
+
+// DefinedRecord 按定义的输出列保存的记录
+type DefinedRecord struct {
+	Values  map[string]string `json:"values"`
+	columns []string
+}
+
+func (r *DefinedRecord) ToRowData() []string {
+	row := make([]string, 0, len(r.columns))
+	for _, column := range r.columns {
+		row = append(row, r.Values[column])
+	}
+	return row
+}
+
+func (r *DefinedRecord) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Values map[string]string `json:"values"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Values == nil {
+		return fmt.Errorf("记录不是按数据集定义保存的")
+	}
+	r.Values = v.Values
+	return nil
+}
+
+// definedCollector 解释执行 DatasetDefinition 的采集器
+type definedCollector struct {
+	definition *DatasetDefinition
+}
+
+func newDefinedCollector(definition *DatasetDefinition) *definedCollector {
+	return &definedCollector{definition: definition}
+}
+
+func (c *definedCollector) Dataset() string {
+	return c.definition.Name
+}
+
+func (c *definedCollector) Headers() []string {
+	return c.definition.Columns()
+}
+
+func (c *definedCollector) NewRecord() Record {
+	return &DefinedRecord{Values: map[string]string{}, columns: c.definition.Columns()}
+}
+
+func (c *definedCollector) Search(edge *PlaywrightEdge) (int, error) {
+	if c.definition.Title != "" {
+		log.Printf("搜索%s", c.definition.Title)
+	}
+	if err := edge.Visit(c.definition.StartURL); err != nil {
+		return 0, err
+	}
+	for i, step := range c.definition.Search {
+		err := runDefinitionStep(edge, &step)
+		if err == nil {
+			continue
+		}
+		if !step.Optional {
+			return 0, fmt.Errorf("第 %d 个搜索步骤 %s 失败: %v", i+1, step.Action, err)
+		}
+		log.Printf("第 %d 个搜索步骤 %s 失败，忽略: %v", i+1, step.Action, err)
+	}
+
+	locator, err := edge.WaitForSelector(c.definition.List.PageCount, 5000)
+	if err != nil {
+		return 0, fmt.Errorf("等待总页数元素失败: %v", err)
+	}
+	text, err := locator.InnerText()
+	if err != nil {
+		return 0, fmt.Errorf("无法获取总页数: %v", err)
+	}
+	page, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("无法转换页码 %q: %v", text, err)
+	}
+	return page, nil
+}
+
+func runDefinitionStep(edge *PlaywrightEdge, step *DefinitionStep) error {
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	// 打开新标签页的步骤由 OpenNewPage 按访问节奏等待，步骤内的点击和按键不再等待
+	click, press := edge.Click, edge.Press
+	if step.NewTab != "" {
+		click = func(locator playwright.Locator) error { return locator.Click() }
+		press = func(locator playwright.Locator, key string) error { return locator.Press(key) }
+	}
+	action := func() error {
+		switch step.Action {
+		case "visit":
+			return edge.Visit(step.URL)
+		case "wait":
+			edge.WaitForIdle(step.Duration)
+			return nil
+		case "save_session":
+			return edge.SaveSession()
+		case "press":
+			if step.Selector == "" {
+				return edge.CurrentPage().Keyboard().Press(step.Key)
+			}
+		}
+
+		locator, err := edge.WaitForSelector(step.Selector, float64(timeout.Milliseconds()))
+		if err != nil {
+			return err
+		}
+		switch step.Action {
+		case "click":
+			return click(locator)
+		case "fill":
+			return locator.Fill(step.Value)
+		case "press":
+			return press(locator, step.Key)
+		case "select":
+			_, err = locator.SelectOption(playwright.SelectOptionValues{Values: &[]string{step.Value}})
+			return err
+		}
+		return nil
+	}
+
+	if step.NewTab == "" {
+		return action()
+	}
+	if _, err := edge.OpenNewPage(step.NewTab, action, float64(timeout.Milliseconds())); err != nil {
+		return err
+	}
+	return edge.SwitchToPage(step.NewTab)
+}
+
+// GoToPage 从分页器的当前页翻到第 to 页，每次翻页后等待当前页变化，避免读到上一页的行；
+// 当前页已是 to 时直接返回，重试时不会重复点击跳过一页
+func (c *definedCollector) GoToPage(edge *PlaywrightEdge, from int, to int) error {
+	list := &c.definition.List
+	active := c.activePage(edge)
+	switch active {
+	case to:
+		return nil
+	case 0:
+		// 读不到当前页时也无法确认翻页是否成功
+		return fmt.Errorf("无法读取分页器的当前页")
+	}
+	if list.PageInput == "" || (to == active+1 && list.NextPage != "") {
+		if to < active {
+			return fmt.Errorf("无法从第 %d 页返回第 %d 页", active, to)
+		}
+		for ; active < to; active++ {
+			locator, err := edge.WaitForSelector(list.NextPage, 1000)
+			if err != nil {
+				return err
+			}
+			if err = edge.Click(locator); err != nil {
+				return err
+			}
+			if err = wait_active_page(active+1, func() int { return c.activePage(edge) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	locator, err := edge.WaitForSelector(list.PageInput, 1000)
+	if err != nil {
+		return err
+	}
+	if err = locator.Clear(); err != nil {
+		return err
+	}
+	if err = locator.Fill(fmt.Sprint(to)); err != nil {
+		return err
+	}
+	if err = edge.Press(locator, "Enter"); err != nil {
+		return err
+	}
+	return wait_active_page(to, func() int { return c.activePage(edge) })
+}
+
+// activePage 分页器的当前页，无法读取时返回 0
+func (c *definedCollector) activePage(edge *PlaywrightEdge) int {
+	text, err := edge.CurrentPage().Locator(c.definition.List.ActivePage).First().InnerText(
+		playwright.LocatorInnerTextOptions{Timeout: playwright.Float(1000)})
+	if err != nil {
+		return 0
+	}
+	page, _ := strconv.Atoi(strings.TrimSpace(text))
+	return page
+}
+
+func (c *definedCollector) ListRows(edge *PlaywrightEdge, page_no int) ([]CollectorRow, error) {
+	list := &c.definition.List
+	// 选择器匹配所有行，只等待第一行，否则严格模式下 WaitFor 会报错
+	locator := edge.CurrentPage().Locator(list.Rows)
+	err := locator.First().WaitFor(playwright.LocatorWaitForOptions{
+		State:   playwright.WaitForSelectorStateVisible,
+		Timeout: playwright.Float(1000),
+	})
+	if err != nil {
+		return nil, err
+	}
+	trs, err := locator.All()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]CollectorRow, 0, len(trs))
+	for idx, tr := range trs {
+		key, err := tr.Locator(list.Key).InnerText()
+		key = strings.TrimSpace(key)
+		if err != nil || key == "" {
+			log.Printf("第 %d 页第 %d 条数据键为空，跳过", page_no, idx+1)
+			if list.StopAtEmpty {
+				break
+			}
+			continue
+		}
+		rows = append(rows, CollectorRow{Index: idx + 1, Key: key, Handle: tr.Locator(list.Detail)})
+	}
+	return rows, nil
+}
+
+func (c *definedCollector) FetchDetail(edge *PlaywrightEdge, row CollectorRow) (Record, error) {
+	return collect_detail_tab(edge, c, row)
+}
+
+func (c *definedCollector) OpenDetail(row CollectorRow) (func() error, float64) {
+	btn := row.Handle.(playwright.Locator)
+	return func() error {
+		return btn.Click()
+	}, float64(c.definition.Detail.Timeout.Milliseconds())
+}
+
+func (c *definedCollector) ParseDetail(edge *PlaywrightEdge, page playwright.Page) (Record, error) {
+	detail := &c.definition.Detail
+	tbody, err := wait_for_selector(page, detail.Table, float64(detail.Timeout.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+	if detail.Ready != "" {
+		if err = waitDefinitionReady(tbody.Locator(detail.Ready), detail.ReadyTimeout); err != nil {
+			return nil, err
+		}
+	}
+	return c.parseDetail(tbody)
+}
+
+// waitDefinitionReady 等待元素的文本不为空，最多等待 timeout
+func waitDefinitionReady(locator playwright.Locator, timeout time.Duration) error {
+	policy := detailReadyPolicy
+	policy.MaxAttempts = int(timeout/policy.Initial) + 1
+	policy.MaxElapsed = timeout
+	return wait_for_text(context.Background(), locator, &policy)
+}
+
+// parseDetail 按标签读取详情表格，表格中没有的字段留空，定义中没有的标签记录到日志
+func (c *definedCollector) parseDetail(tbody playwright.Locator) (Record, error) {
+	detail := &c.definition.Detail
+	cells, err := ReadDetailCells(tbody, detail.Label, detail.Value)
+	if err != nil {
+		return nil, err
+	}
+	record := c.NewRecord().(*DefinedRecord)
+	values, report, err := c.definition.labels.Match(cells)
+	if err != nil {
+		return nil, fmt.Errorf("%s 详情表格与定义不一致: %w", c.definition.Name, err)
+	}
+	for column, value := range values {
+		record.Values[column] = value
+	}
+	if !report.OK() {
+		log.Printf("%s 详情表格与定义不一致: %s", c.definition.Name, report)
+	}
+	log.Printf("...采集 %s", strings.Join(record.ToRowData()[:min(2, len(c.definition.Fields))], " "))
+	return record, nil
+}
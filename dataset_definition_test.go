@@ -0,0 +1,131 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+func TestDefinedGoToPage(t *testing.T) {
+	list := DefinitionList{
+		NextPage:   "div.el-pagination > button.btn-next",
+		ActivePage: "div.el-pagination > ul.el-pager > li.active",
+		PageInput:  "div.el-pagination__editor > input",
+		Rows:       "table > tbody > tr",
+		Key:        "td:nth-child(2) > div > p",
+		Detail:     "td:nth-child(5) > div > button",
+	}
+	nextOnly := list
+	nextOnly.PageInput = ""
+	noPager := list
+	noPager.ActivePage = "div.missing"
+
+	tests := []struct {
+		name       string
+		list       DefinitionList
+		active     int
+		from, to   int
+		stuck      bool
+		wantActive int
+		wantClicks int
+		wantErr    bool
+	}{
+		{name: "相邻页点击下一页", list: list, active: 1, from: 1, to: 2, wantActive: 2, wantClicks: 1},
+		{name: "非相邻页输入页码跳转", list: list, active: 1, from: 1, to: 6, wantActive: 6, wantClicks: 1},
+		{name: "没有页码输入框时逐页点击", list: nextOnly, active: 1, from: 1, to: 4, wantActive: 4, wantClicks: 3},
+		{name: "已在目标页时不再点击", list: nextOnly, active: 3, from: 2, to: 3, wantActive: 3, wantClicks: 0},
+		{name: "没有页码输入框时不能向前翻页", list: nextOnly, active: 4, from: 4, to: 2, wantActive: 4, wantErr: true},
+		{name: "读不到当前页", list: noPager, active: 1, from: 1, to: 2, wantErr: true},
+		{name: "翻页后当前页不变", list: list, active: 1, from: 1, to: 2, stuck: true, wantActive: 1, wantClicks: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := &fakePager{active: tt.active, render: func(active int) string { return nmpaListPage(active, "国械注进1") }}
+			site := fake.NewSite().
+				Page(nmpaListURL, pager.render(tt.active)).
+				OnClick(nmpaListURL, list.NextPage, pager.next(tt.stuck)).
+				OnPress(nmpaListURL, list.PageInput, "Enter", pager.jump(list.PageInput))
+			edge := newFakeEdge(t, site, nmpaListURL)
+			collector := newDefinedCollector(&DatasetDefinition{List: tt.list})
+
+			err := collector.GoToPage(edge, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GoToPage(%d, %d) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if got := collector.activePage(edge); got != tt.wantActive {
+				t.Errorf("当前页 = %d, want %d", got, tt.wantActive)
+			}
+			if pager.clicks != tt.wantClicks {
+				t.Errorf("翻页 %d 次, want %d", pager.clicks, tt.wantClicks)
+			}
+		})
+	}
+}
+
+func TestDefinedListRows(t *testing.T) {
+	list := DefinitionList{
+		Rows:   "table > tbody > tr",
+		Key:    "td:nth-child(2) > div > p",
+		Detail: "td:nth-child(5) > div > button",
+	}
+	stopAtEmpty := list
+	stopAtEmpty.StopAtEmpty = true
+
+	tests := []struct {
+		name      string
+		list      DefinitionList
+		keys      []string
+		wantKeys  []string
+		wantIndex []int
+	}{
+		{"所有行", list, []string{"国械注进1", "国械注进2"}, []string{"国械注进1", "国械注进2"}, []int{1, 2}},
+		{"跳过键为空的行", list, []string{"国械注进1", "", "国械注进3"}, []string{"国械注进1", "国械注进3"}, []int{1, 3}},
+		{"stop_at_empty 时遇到键为空的行结束", stopAtEmpty, []string{"国械注进1", "", "国械注进3"}, []string{"国械注进1"}, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := fake.NewSite().Page(nmpaListURL, nmpaListPage(1, tt.keys...))
+			edge := newFakeEdge(t, site, nmpaListURL)
+
+			rows, err := newDefinedCollector(&DatasetDefinition{List: tt.list}).ListRows(edge, 1)
+			if err != nil {
+				t.Fatalf("ListRows() error = %v", err)
+			}
+			checkRows(t, rows, tt.wantKeys, tt.wantIndex, "详情 ")
+		})
+	}
+}
+
+// datasets/examples 中的示例与内置数据集同名，列与内置采集器一致；默认目录不加载子目录中的示例
+func TestExampleDefinitions(t *testing.T) {
+	definitions, err := LoadDatasetDefinitions(filepath.Join("datasets", "examples"))
+	if err != nil {
+		t.Fatalf("LoadDatasetDefinitions() error = %v", err)
+	}
+	if len(definitions) != 2 {
+		t.Fatalf("加载了 %d 个示例, want 2", len(definitions))
+	}
+	for _, definition := range definitions {
+		if err := definition.validate(); err != nil {
+			t.Errorf("%s: validate() error = %v", definition.path, err)
+		}
+		builtin, err := NewCollector(definition.Name)
+		if err != nil {
+			t.Errorf("%s: %v", definition.path, err)
+			continue
+		}
+		if !definition.Override {
+			t.Errorf("%s: 与内置数据集同名的示例应设置 override", definition.path)
+		}
+		if got, want := definition.Columns(), builtin.Headers(); !slices.Equal(got, want) {
+			t.Errorf("%s: 输出列 = %v, want %v", definition.path, got, want)
+		}
+	}
+
+	defaults, err := LoadDatasetDefinitions("datasets")
+	if err != nil || len(defaults) != 0 {
+		t.Errorf("datasets 目录加载了 %d 个定义, error = %v, want 0", len(defaults), err)
+	}
+}
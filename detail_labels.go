@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// LabelField 详情表格中一个标签对应的字段
+type LabelField struct {
+	Field   string   // 结构体字段名（或输出列名）
+	Label   string   // 页面上的标签
+	Aliases []string // 标签的其他写法，例如改版前后的名称
+}
+
+// LabelDictionary 详情表格的标签字典，按每行的标签单元格取值，不依赖行的顺序
+type LabelDictionary struct {
+	fields []LabelField
+	index  map[string]int // 规范化后的标签 -> fields 下标
+}
+
+// NewLabelDictionary 创建标签字典，标签（含别名）重复时 panic，字典通常定义为包级变量
+func NewLabelDictionary(fields []LabelField) *LabelDictionary {
+	d, err := newLabelDictionary(fields)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func newLabelDictionary(fields []LabelField) (*LabelDictionary, error) {
+	d := &LabelDictionary{fields: fields, index: make(map[string]int)}
+	for i, field := range fields {
+		for _, label := range append([]string{field.Label}, field.Aliases...) {
+			label = normalizeLabel(label)
+			if j, ok := d.index[label]; ok {
+				return nil, fmt.Errorf("标签 %s 同时对应 %s 和 %s", label, fields[j].Field, field.Field)
+			}
+			d.index[label] = i
+		}
+	}
+	return d, nil
+}
+
+// DetailCell 详情表格的一行
+type DetailCell struct {
+	Label string
+	Value string
+}
+
+// ErrNoLabels 详情表格不为空，但没有识别出任何标签，通常是站点改版或标签单元格的选择器失效。
+// 此时不按行的顺序取值，以免把错位的值写入输出
+var ErrNoLabels = errors.New("详情表格中没有识别出任何标签")
+
+// LabelReport 按标签取值的结果，用于发现站点改版
+type LabelReport struct {
+	Unknown []string // 表格中有、字典中没有的标签
+	Missing []string // 字典中有、表格中没有的标签
+}
+
+// OK 所有标签都已识别且没有缺失
+func (r *LabelReport) OK() bool {
+	return len(r.Unknown) == 0 && len(r.Missing) == 0
+}
+
+func (r *LabelReport) String() string {
+	parts := []string{}
+	if len(r.Unknown) > 0 {
+		parts = append(parts, "未知标签: "+strings.Join(r.Unknown, ", "))
+	}
+	if len(r.Missing) > 0 {
+		parts = append(parts, "缺少标签: "+strings.Join(r.Missing, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ReadDetailCells 读取详情表格的每一行，缺少标签或值单元格的行留空，不会等待不存在的单元格
+func ReadDetailCells(tbody playwright.Locator, label_selector string, value_selector string) ([]DetailCell, error) {
+	items, err := tbody.Locator("tr").All()
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]DetailCell, 0, len(items))
+	for _, item := range items {
+		label, err := cellText(item, label_selector)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cellText(item, value_selector)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, DetailCell{Label: label, Value: value})
+	}
+	return cells, nil
+}
+
+func cellText(row playwright.Locator, selector string) (string, error) {
+	cell := row.Locator(selector)
+	count, err := cell.Count()
+	if err != nil || count == 0 {
+		return "", err
+	}
+	text, err := cell.First().InnerText()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// Match 按标签把表格的值对应到字段，返回 字段 -> 值。
+// 表格不为空却没有识别出任何标签时（例如标签单元格的选择器失效）返回 ErrNoLabels
+func (d *LabelDictionary) Match(cells []DetailCell) (map[string]string, *LabelReport, error) {
+	values := make(map[string]string, len(d.fields))
+	report := &LabelReport{}
+	found := make([]bool, len(d.fields))
+	for _, cell := range cells {
+		if cell.Label == "" && cell.Value == "" {
+			continue
+		}
+		i, ok := d.index[normalizeLabel(cell.Label)]
+		if !ok {
+			label := cell.Label
+			if label == "" {
+				label = "（空）"
+			}
+			report.Unknown = append(report.Unknown, label)
+			continue
+		}
+		if !found[i] {
+			values[d.fields[i].Field] = cell.Value
+			found[i] = true
+		}
+	}
+
+	for i, field := range d.fields {
+		if !found[i] {
+			report.Missing = append(report.Missing, field.Label)
+		}
+	}
+	if len(values) == 0 && slices.ContainsFunc(cells, func(cell DetailCell) bool { return cell.Label != "" || cell.Value != "" }) {
+		return nil, report, fmt.Errorf("%w: %s", ErrNoLabels, report)
+	}
+	return values, report, nil
+}
+
+// Lookup 按标签（含别名）查找字段 field 在表格中的值，找不到该标签时返回 false
+func (d *LabelDictionary) Lookup(cells []DetailCell, field string) (string, bool) {
+	for _, cell := range cells {
+		if i, ok := d.index[normalizeLabel(cell.Label)]; ok && d.fields[i].Field == field {
+			return cell.Value, true
+		}
+	}
+	return "", false
+}
+
+// Fill 按标签给结构体指针 record 的字符串字段赋值，返回识别结果；没有识别出任何标签时不赋值，返回 ErrNoLabels
+func (d *LabelDictionary) Fill(record any, cells []DetailCell) (*LabelReport, error) {
+	values, report, err := d.Match(cells)
+	if err != nil {
+		return report, err
+	}
+	v := reflect.ValueOf(record).Elem()
+	for name, value := range values {
+		if field := v.FieldByName(name); field.IsValid() && field.Kind() == reflect.String {
+			field.SetString(value)
+		}
+	}
+	return report, nil
+}
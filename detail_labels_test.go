@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+var testLabels = NewLabelDictionary([]LabelField{
+	{Field: "RegisterNo", Label: "注册证号", Aliases: []string{"批准文号"}},
+	{Field: "ProductName", Label: "产品名称（中文）"},
+	{Field: "Holder", Label: "上市许可持有人"},
+})
+
+type testLabelRecord struct {
+	RegisterNo  string
+	ProductName string
+	Holder      string
+	Pages       int // 非字符串字段不赋值
+}
+
+func TestLabelDictionaryMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		cells       []DetailCell
+		want        map[string]string
+		wantUnknown []string
+		wantMissing []string
+		wantErr     bool
+	}{
+		{
+			name: "按标签取值，与行顺序无关",
+			cells: []DetailCell{
+				{"上市许可持有人", "某药业"},
+				{"注册证号", "H20000001"},
+				{"产品名称（中文）", "某某片"},
+			},
+			want: map[string]string{"RegisterNo": "H20000001", "ProductName": "某某片", "Holder": "某药业"},
+		},
+		{
+			name: "别名和标签的空白、冒号",
+			cells: []DetailCell{
+				{"批准文号：", "H20000001"},
+				{" 产品名称（中文） ", "某某片"},
+				{"上市许可持有人:", "某药业"},
+			},
+			want: map[string]string{"RegisterNo": "H20000001", "ProductName": "某某片", "Holder": "某药业"},
+		},
+		{
+			name: "未知标签",
+			cells: []DetailCell{
+				{"注册证号", "H20000001"},
+				{"产品名称（中文）", "某某片"},
+				{"上市许可持有人", "某药业"},
+				{"包装规格", "10片/盒"},
+			},
+			want:        map[string]string{"RegisterNo": "H20000001", "ProductName": "某某片", "Holder": "某药业"},
+			wantUnknown: []string{"包装规格"},
+		},
+		{
+			name: "缺少标签",
+			cells: []DetailCell{
+				{"注册证号", "H20000001"},
+				{"", ""},
+			},
+			want:        map[string]string{"RegisterNo": "H20000001"},
+			wantMissing: []string{"产品名称（中文）", "上市许可持有人"},
+		},
+		{
+			name: "重复标签取第一次出现的值",
+			cells: []DetailCell{
+				{"注册证号", "H20000001"},
+				{"批准文号", "H20000002"},
+				{"产品名称（中文）", "某某片"},
+				{"上市许可持有人", "某药业"},
+			},
+			want: map[string]string{"RegisterNo": "H20000001", "ProductName": "某某片", "Holder": "某药业"},
+		},
+		{
+			name: "标签为空时不按行顺序取值",
+			cells: []DetailCell{
+				{"", "H20000001"},
+				{"", "某某片"},
+			},
+			wantUnknown: []string{"（空）", "（空）"},
+			wantMissing: []string{"注册证号", "产品名称（中文）", "上市许可持有人"},
+			wantErr:     true,
+		},
+		{
+			name: "没有识别出任何标签",
+			cells: []DetailCell{
+				{"Registration No.", "H20000001"},
+				{"Product", "某某片"},
+			},
+			wantUnknown: []string{"Registration No.", "Product"},
+			wantMissing: []string{"注册证号", "产品名称（中文）", "上市许可持有人"},
+			wantErr:     true,
+		},
+		{
+			name:        "只有空行",
+			cells:       []DetailCell{{"", ""}},
+			want:        map[string]string{},
+			wantMissing: []string{"注册证号", "产品名称（中文）", "上市许可持有人"},
+		},
+		{
+			name:        "空表格",
+			cells:       nil,
+			want:        map[string]string{},
+			wantMissing: []string{"注册证号", "产品名称（中文）", "上市许可持有人"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := testLabels.Match(tt.cells)
+			if errors.Is(err, ErrNoLabels) != tt.wantErr {
+				t.Errorf("Match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(report.Unknown, tt.wantUnknown) {
+				t.Errorf("Unknown = %v, want %v", report.Unknown, tt.wantUnknown)
+			}
+			if !slices.Equal(report.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", report.Missing, tt.wantMissing)
+			}
+			wantOK := len(tt.wantUnknown) == 0 && len(tt.wantMissing) == 0
+			if report.OK() != wantOK {
+				t.Errorf("OK() = %v, want %v (%s)", report.OK(), wantOK, report)
+			}
+		})
+	}
+}
+
+func TestLabelDictionaryFill(t *testing.T) {
+	tests := []struct {
+		name    string
+		cells   []DetailCell
+		want    testLabelRecord
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name: "所有标签",
+			cells: []DetailCell{
+				{"产品名称（中文）", "某某片"},
+				{"批准文号", "H20000001"},
+				{"上市许可持有人", "某药业"},
+			},
+			want:   testLabelRecord{RegisterNo: "H20000001", ProductName: "某某片", Holder: "某药业"},
+			wantOK: true,
+		},
+		{
+			name:  "缺少标签的字段保持原值",
+			cells: []DetailCell{{"注册证号", "H20000001"}, {"备注", "无"}},
+			want:  testLabelRecord{RegisterNo: "H20000001", Holder: "原值"},
+		},
+		{
+			name:    "没有识别出任何标签时不赋值",
+			cells:   []DetailCell{{"", "H20000001"}, {"", "某某片"}, {"", "某药业"}},
+			want:    testLabelRecord{Holder: "原值"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &testLabelRecord{Holder: "原值"}
+			report, err := testLabels.Fill(record, tt.cells)
+			if errors.Is(err, ErrNoLabels) != tt.wantErr {
+				t.Errorf("Fill() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if *record != tt.want {
+				t.Errorf("Fill() = %+v, want %+v", *record, tt.want)
+			}
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v (%s)", report.OK(), tt.wantOK, report)
+			}
+		})
+	}
+}
+
+func TestNewLabelDictionaryDuplicate(t *testing.T) {
+	_, err := newLabelDictionary([]LabelField{
+		{Field: "RegisterNo", Label: "注册证号"},
+		{Field: "AuthCode", Label: "批准文号", Aliases: []string{"注册证号："}},
+	})
+	if err == nil {
+		t.Error("标签重复时 newLabelDictionary 应返回错误")
+	}
+}
+
+// 详情表格中有的行缺少值单元格，标签单元格选择器失效时所有标签为空，Match 返回 ErrNoLabels
+func TestReadDetailCells(t *testing.T) {
+	const url = "https://www.nmpa.gov.cn/datasearch/search-info.html"
+	site := fake.NewSite().Page(url, `<html><body><table><tbody>
+		<tr><td>注册证号</td><td> H20000001 </td></tr>
+		<tr><td>产品名称（中文）</td></tr>
+		<tr><td>上市许可持有人</td><td>某药业</td></tr>
+	</tbody></table></body></html>`)
+	edge := newFakeEdge(t, site, url)
+	tbody := edge.CurrentPage().Locator("table > tbody")
+
+	cells, err := ReadDetailCells(tbody, "td:nth-child(1)", "td:nth-child(2)")
+	if err != nil {
+		t.Fatalf("ReadDetailCells() error = %v", err)
+	}
+	want := []DetailCell{{"注册证号", "H20000001"}, {"产品名称（中文）", ""}, {"上市许可持有人", "某药业"}}
+	if !slices.Equal(cells, want) {
+		t.Errorf("ReadDetailCells() = %v, want %v", cells, want)
+	}
+	for _, tt := range []struct {
+		field  string
+		want   string
+		wantOK bool
+	}{
+		{"RegisterNo", "H20000001", true},
+		{"ProductName", "", true},
+		{"Pages", "", false},
+	} {
+		if got, ok := testLabels.Lookup(cells, tt.field); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%s) = %q, %v, want %q, %v", tt.field, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	cells, err = ReadDetailCells(tbody, "th", "td:nth-child(2)")
+	if err != nil {
+		t.Fatalf("ReadDetailCells() error = %v", err)
+	}
+	if values, _, err := testLabels.Match(cells); !errors.Is(err, ErrNoLabels) || values != nil {
+		t.Errorf("标签选择器失效时 Match() = %v, %v, want ErrNoLabels", values, err)
+	}
+	if _, ok := testLabels.Lookup(cells, "RegisterNo"); ok {
+		t.Error("标签选择器失效时 Lookup 不应按行顺序退回")
+	}
+}
+
+func TestLabelReportString(t *testing.T) {
+	report := &LabelReport{Unknown: []string{"包装规格"}, Missing: []string{"注册证号"}}
+	for _, want := range []string{"包装规格", "注册证号"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("String() = %q, 缺少 %q", report, want)
+		}
+	}
+}
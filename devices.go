@@ -0,0 +1,130 @@
+package main
+
+// DeviceRegistration 医疗器械注册证或备案信息，注册和备案的详情表格标签不同，按含义合并为同一结构
+type DeviceRegistration struct {
+	Category           string // 分类，如 境内医疗器械（注册）
+	RegisterNo         string // 注册证编号/备案号
+	Registrant         string // 注册人名称/备案人名称
+	RegistrantAddress  string // 注册人住所/备案人注册地址
+	ManufactureAddress string // 生产地址
+	AgentName          string // 代理人名称
+	AgentAddress       string // 代理人住所
+	ProductName        string // 产品名称
+	Specification      string // 型号、规格
+	Structure          string // 结构及组成/产品描述
+	Scope              string // 适用范围/预期用途
+	MainComponents     string // 主要组成成分（体外诊断试剂）
+	StorageConditions  string // 产品储存条件及有效期（体外诊断试剂）
+	OtherContent       string // 其他内容
+	Remark             string // 备注
+	ApprovalDepartment string // 审批部门/备案单位
+	ApprovalDate       string // 批准日期/备案日期
+	ValidUntil         string // 有效期至
+	ChangeInfo         string // 变更情况
+}
+
+// deviceRegistrationLabels 医疗器械注册和备案详情表格的标签
+var deviceRegistrationLabels = NewLabelDictionary([]LabelField{
+	{Field: "RegisterNo", Label: "注册证编号", Aliases: []string{"备案号", "备案编号"}},
+	{Field: "Registrant", Label: "注册人名称", Aliases: []string{"备案人名称"}},
+	{Field: "RegistrantAddress", Label: "注册人住所", Aliases: []string{"备案人注册地址", "备案人住所"}},
+	{Field: "ManufactureAddress", Label: "生产地址"},
+	{Field: "AgentName", Label: "代理人名称", Aliases: []string{"代理人"}},
+	{Field: "AgentAddress", Label: "代理人住所", Aliases: []string{"代理人注册地址"}},
+	{Field: "ProductName", Label: "产品名称", Aliases: []string{"产品名称（中文）"}},
+	{Field: "Specification", Label: "型号、规格", Aliases: []string{"型号/规格", "型号规格", "包装规格"}},
+	{Field: "Structure", Label: "结构及组成", Aliases: []string{"产品描述"}},
+	{Field: "Scope", Label: "适用范围", Aliases: []string{"预期用途", "预期用途（体外诊断试剂）"}},
+	{Field: "MainComponents", Label: "主要组成成分（体外诊断试剂）"},
+	{Field: "StorageConditions", Label: "产品储存条件及有效期（体外诊断试剂）"},
+	{Field: "OtherContent", Label: "其他内容"},
+	{Field: "Remark", Label: "备注"},
+	{Field: "ApprovalDepartment", Label: "审批部门", Aliases: []string{"备案单位"}},
+	{Field: "ApprovalDate", Label: "批准日期", Aliases: []string{"备案日期"}},
+	{Field: "ValidUntil", Label: "有效期至"},
+	{Field: "ChangeInfo", Label: "变更情况"},
+})
+
+func GetDeviceRegistrationHeaders() []string {
+	return []string{
+		"分类",
+		"注册证编号/备案号",
+		"注册人/备案人名称",
+		"注册人住所/备案人注册地址",
+		"生产地址",
+		"代理人名称",
+		"代理人住所",
+		"产品名称",
+		"型号、规格",
+		"结构及组成/产品描述",
+		"适用范围/预期用途",
+		"主要组成成分（体外诊断试剂）",
+		"产品储存条件及有效期（体外诊断试剂）",
+		"其他内容",
+		"备注",
+		"审批部门/备案单位",
+		"批准日期/备案日期",
+		"有效期至",
+		"变更情况",
+	}
+}
+
+func (device *DeviceRegistration) ToRowData() []string {
+	return []string{
+		device.Category,
+		device.RegisterNo,
+		device.Registrant,
+		device.RegistrantAddress,
+		device.ManufactureAddress,
+		device.AgentName,
+		device.AgentAddress,
+		device.ProductName,
+		device.Specification,
+		device.Structure,
+		device.Scope,
+		device.MainComponents,
+		device.StorageConditions,
+		device.OtherContent,
+		device.Remark,
+		device.ApprovalDepartment,
+		device.ApprovalDate,
+		device.ValidUntil,
+		device.ChangeInfo,
+	}
+}
+
+// deviceListColumns 医疗器械列表：序号、注册证编号（备案编号）、产品名称、注册人（备案人）、批准日期、详情按钮。
+// 单元格中没有药品列表的 <p>，详情按钮在最后一列；列的顺序尚未在线上页面核对
+var deviceListColumns = nmpaListColumns{
+	key:    "td:nth-child(2)",
+	detail: "td:last-child button",
+}
+
+// 医疗器械的四个分类：数据集名称 -> 首页分类链接的标题
+var deviceCategories = map[string]string{
+	"devices_domestic":        "境内医疗器械（注册）",
+	"devices_domestic_filing": "境内医疗器械（备案）",
+	"devices_import":          "进口医疗器械（注册）",
+	"devices_import_filing":   "进口医疗器械（备案）",
+}
+
+func init() {
+	for dataset, title := range deviceCategories {
+		RegisterCollector(dataset, func() Collector {
+			return &nmpaCategoryCollector{
+				dataset:    dataset,
+				category:   "ylqx",
+				title:      title,
+				columns:    deviceListColumns,
+				headers:    GetDeviceRegistrationHeaders(),
+				labels:     deviceRegistrationLabels,
+				readyField: "RegisterNo",
+				newRecord:  func() Record { return &DeviceRegistration{Category: title} },
+				summary: func(record Record) string {
+					device := record.(*DeviceRegistration)
+					return device.ProductName + " " + device.RegisterNo
+				},
+			}
+		})
+	}
+}
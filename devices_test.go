@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+// deviceListPage 医疗器械列表页，单元格中直接是文字，详情按钮在最后一列
+func deviceListPage(keys ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div class="el-table"><table><tbody>`)
+	for i, key := range keys {
+		fmt.Fprintf(&sb, `<tr><td><div class="cell">%d</div></td><td><div class="cell">%s</div></td><td><div class="cell">某某导管</div></td>`, i+1, key)
+		fmt.Fprintf(&sb, `<td><div class="cell">某医疗器械公司</div></td><td><div class="cell">2020-01-01</div></td><td><div class="cell"><button>详情 %s</button></div></td></tr>`, key)
+	}
+	sb.WriteString(`</tbody></table></div></body></html>`)
+	return sb.String()
+}
+
+func TestDeviceListRows(t *testing.T) {
+	site := fake.NewSite().Page(nmpaListURL, deviceListPage("国械注进20203010001", " ", "国械注进20203010003"))
+	edge := newFakeEdge(t, site, nmpaListURL)
+
+	for _, dataset := range []string{"devices_domestic", "devices_domestic_filing", "devices_import", "devices_import_filing"} {
+		t.Run(dataset, func(t *testing.T) {
+			collector, err := NewCollector(dataset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := collector.ListRows(edge, 1)
+			if err != nil {
+				t.Fatalf("ListRows() error = %v", err)
+			}
+			checkRows(t, rows, []string{"国械注进20203010001", "国械注进20203010003"}, []int{1, 3}, "详情 ")
+		})
+	}
+
+	// 药品列表的列在医疗器械列表中找不到注册证号
+	rows, err := (&nmpaCategoryCollector{columns: nmpaDrugColumns}).ListRows(edge, 1)
+	if err != nil || len(rows) != 0 {
+		t.Errorf("按药品列表的列读取 = %d 行, %v, want 0 行", len(rows), err)
+	}
+}
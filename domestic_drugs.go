@@ -0,0 +1,99 @@
+package main
+
+// DomesticDrug 境内生产药品（国药准字）
+type DomesticDrug struct {
+	ApprovalNo             string // 批准文号
+	SourceApprovalNo       string // 原批准文号
+	ProductName            string // 产品名称
+	ProductNameEN          string // 英文名称
+	BrandName              string // 商品名
+	TorchType              string // 剂型
+	Specification          string // 规格
+	CertHolder             string // 上市许可持有人
+	CertHolderAddress      string // 上市许可持有人地址
+	Manufacturer           string // 生产单位
+	ManufacturerAddress    string // 生产地址
+	ProductCategory        string // 产品类别
+	ApprovalDate           string // 批准日期
+	DrugStandardCode       string // 药品本位码
+	DrugStandardCodeRemark string // 药品本位码备注
+}
+
+// domesticDrugLabels 境内生产药品详情表格的标签
+var domesticDrugLabels = NewLabelDictionary([]LabelField{
+	{Field: "ApprovalNo", Label: "批准文号"},
+	{Field: "SourceApprovalNo", Label: "原批准文号"},
+	{Field: "ProductName", Label: "产品名称", Aliases: []string{"产品名称（中文）"}},
+	{Field: "ProductNameEN", Label: "英文名称", Aliases: []string{"产品名称（英文）"}},
+	{Field: "BrandName", Label: "商品名", Aliases: []string{"商品名称"}},
+	{Field: "TorchType", Label: "剂型"},
+	{Field: "Specification", Label: "规格"},
+	{Field: "CertHolder", Label: "上市许可持有人"},
+	{Field: "CertHolderAddress", Label: "上市许可持有人地址"},
+	{Field: "Manufacturer", Label: "生产单位", Aliases: []string{"生产企业"}},
+	{Field: "ManufacturerAddress", Label: "生产地址"},
+	{Field: "ProductCategory", Label: "产品类别"},
+	{Field: "ApprovalDate", Label: "批准日期"},
+	{Field: "DrugStandardCode", Label: "药品本位码"},
+	{Field: "DrugStandardCodeRemark", Label: "药品本位码备注"},
+})
+
+func GetDomesticDrugHeaders() []string {
+	return []string{
+		"批准文号",
+		"原批准文号",
+		"产品名称",
+		"英文名称",
+		"商品名",
+		"剂型",
+		"规格",
+		"上市许可持有人",
+		"上市许可持有人地址",
+		"生产单位",
+		"生产地址",
+		"产品类别",
+		"批准日期",
+		"药品本位码",
+		"药品本位码备注",
+	}
+}
+
+func (drug *DomesticDrug) ToRowData() []string {
+	return []string{
+		drug.ApprovalNo,
+		drug.SourceApprovalNo,
+		drug.ProductName,
+		drug.ProductNameEN,
+		drug.BrandName,
+		drug.TorchType,
+		drug.Specification,
+		drug.CertHolder,
+		drug.CertHolderAddress,
+		drug.Manufacturer,
+		drug.ManufacturerAddress,
+		drug.ProductCategory,
+		drug.ApprovalDate,
+		drug.DrugStandardCode,
+		drug.DrugStandardCodeRemark,
+	}
+}
+
+func init() {
+	RegisterCollector("domestic_drugs", func() Collector {
+		return &nmpaCategoryCollector{
+			dataset:    "domestic_drugs",
+			category:   "yp",
+			keyword:    "药",
+			title:      "境内生产药品",
+			columns:    nmpaDrugColumns,
+			headers:    GetDomesticDrugHeaders(),
+			labels:     domesticDrugLabels,
+			readyField: "ApprovalNo",
+			newRecord:  func() Record { return &DomesticDrug{} },
+			summary: func(record Record) string {
+				drug := record.(*DomesticDrug)
+				return drug.ProductName + " " + drug.ApprovalNo
+			},
+		}
+	})
+}
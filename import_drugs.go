@@ -1,10 +1,7 @@
 package main
 
 import (
-	"log"
-	"strconv"
 	"strings"
-	"time"
 
 	"fmt"
 
@@ -50,51 +47,45 @@ type MedicineData struct {
 	DrugStandardCodeRemark   string // 药品本位码备注
 }
 
-func NewMedicineData(lines []string) *MedicineData {
-	if len(lines) < 36 {
-		for i := len(lines); i < 36; i++ {
-			lines = append(lines, "")
-		}
-	}
-	return &MedicineData{
-		RegisterNo:               lines[0],
-		SourceRegisterNo:         lines[1],
-		RegisterRemark:           lines[2],
-		SubPackageAuthCode:       lines[3],
-		CertHolderCN:             lines[4],
-		CertHolderEN:             lines[5],
-		CertHolderAddressCN:      lines[6],
-		CertHolderAddressEN:      lines[7],
-		CompanyNameCN:            lines[8],
-		CompanyNameEN:            lines[9],
-		CompanyAddressCN:         lines[10],
-		CompanyAddressEN:         lines[11],
-		CompanyRegionCN:          lines[12],
-		CompanyRegionEN:          lines[13],
-		ProductNameCN:            lines[14],
-		ProductNameEN:            lines[15],
-		BrandNameCN:              lines[16],
-		BrandNameEN:              lines[17],
-		TorchTypeCN:              lines[18],
-		SpecificationCN:          lines[19],
-		PackageSpecCN:            lines[20],
-		ManufacturerCN:           lines[21],
-		ManufacturerEN:           lines[22],
-		ManufacturerAddressCN:    lines[23],
-		ManufacturerAddressEN:    lines[24],
-		ManufacturerRegionCN:     lines[25],
-		ManufacturerRegionEN:     lines[26],
-		CertStartDate:            lines[27],
-		CertEndDate:              lines[28],
-		SubPackageCompanyName:    lines[29],
-		SubPackageCompanyAddress: lines[30],
-		SubPackageCertStartDate:  lines[31],
-		SubPackageCertEndDate:    lines[32],
-		DrugStandardCode:         lines[33],
-		ProductCategory:          lines[34],
-		DrugStandardCodeRemark:   lines[35],
-	}
-}
+// medicineDataLabels 境外生产药品详情表格的标签，顺序与详情表格的行顺序一致
+var medicineDataLabels = NewLabelDictionary([]LabelField{
+	{Field: "RegisterNo", Label: "注册证号"},
+	{Field: "SourceRegisterNo", Label: "原注册证号"},
+	{Field: "RegisterRemark", Label: "注册证号备注"},
+	{Field: "SubPackageAuthCode", Label: "分包装批准文号"},
+	{Field: "CertHolderCN", Label: "上市许可持有人（中文）", Aliases: []string{"上市许可证持有人（中文）", "上市许可证只有人（中文）"}},
+	{Field: "CertHolderEN", Label: "上市许可持有人（英文）", Aliases: []string{"上市许可证持有人（英文）", "上市许可证只有人（英文）"}},
+	{Field: "CertHolderAddressCN", Label: "上市许可持有人地址（中文）", Aliases: []string{"上市许可证持有人地址（中文）"}},
+	{Field: "CertHolderAddressEN", Label: "上市许可持有人地址（英文）", Aliases: []string{"上市许可证持有人地址（英文）"}},
+	{Field: "CompanyNameCN", Label: "公司名称（中文）"},
+	{Field: "CompanyNameEN", Label: "公司名称（英文）"},
+	{Field: "CompanyAddressCN", Label: "地址（中文）"},
+	{Field: "CompanyAddressEN", Label: "地址（英文）"},
+	{Field: "CompanyRegionCN", Label: "国家/地区（中文）"},
+	{Field: "CompanyRegionEN", Label: "国家/地区（英文）"},
+	{Field: "ProductNameCN", Label: "产品名称（中文）"},
+	{Field: "ProductNameEN", Label: "产品名称（英文）"},
+	{Field: "BrandNameCN", Label: "商品名称（中文）"},
+	{Field: "BrandNameEN", Label: "商品名称（英文）"},
+	{Field: "TorchTypeCN", Label: "剂型（中文）"},
+	{Field: "SpecificationCN", Label: "规格（中文）"},
+	{Field: "PackageSpecCN", Label: "包装规格（中文）"},
+	{Field: "ManufacturerCN", Label: "生产厂商（中文）"},
+	{Field: "ManufacturerEN", Label: "生产厂商（英文）"},
+	{Field: "ManufacturerAddressCN", Label: "生产厂商地址（中文）"},
+	{Field: "ManufacturerAddressEN", Label: "生产厂商地址（英文）"},
+	{Field: "ManufacturerRegionCN", Label: "厂商国家/地区（中文）"},
+	{Field: "ManufacturerRegionEN", Label: "厂商国家/地区（英文）"},
+	{Field: "CertStartDate", Label: "发证日期", Aliases: []string{"批准日期"}},
+	{Field: "CertEndDate", Label: "有效期截止日"},
+	{Field: "SubPackageCompanyName", Label: "分包装企业名称"},
+	{Field: "SubPackageCompanyAddress", Label: "分包装企业地址"},
+	{Field: "SubPackageCertStartDate", Label: "分包装文号批准日期"},
+	{Field: "SubPackageCertEndDate", Label: "分包装文号有效期截止日"},
+	{Field: "DrugStandardCode", Label: "药品本位码"},
+	{Field: "ProductCategory", Label: "产品类别"},
+	{Field: "DrugStandardCodeRemark", Label: "药品本位码备注"},
+})
 
 func GetMedicineDataHeaders() []string {
 	return []string{
@@ -179,304 +170,133 @@ func (medicine *MedicineData) ToRowData() []string {
 }
 
 func search_jinkouyao(edge *PlaywrightEdge) (int, error) {
-	// 打开进口原研药列表页面
-	err := edge.Visit("https://www.nmpa.gov.cn/datasearch/home-index.html#category=yp")
-	if err != nil {
-		return 0, err
-	}
-
-	time.Sleep(10 * time.Second)
-
-	if err = edge.CurrentPage().Keyboard().Press("Escape"); err != nil {
-		log.Printf("按键 escape 失败: %v", err)
-	} else {
-		log.Println("按键 escape 成功")
-	}
-
-	// 点击境外生产药品
-	locator, err := edge.WaitForSelector("div.el-col.el-col-8 a[title='境外生产药品']", 10000)
-	if err != nil {
-		return 0, err
-	}
-	locator.Click()
-
-	// 定位搜索输入框
-	locator, err = edge.WaitForSelector("div.search-input.el-input.el-input-group.el-input-group--append input", 3000)
-	if err != nil {
-		return 0, err
-	}
-	locator.Fill("药")
-
-	// 回车搜索
-	_, err = edge.OpenNewPage("列表页", func() error {
-		return locator.Press("Enter")
-	}, 3000)
-	if err != nil {
-		return 0, err
-	}
-	// 切换到新页面
-	edge.SwitchToNextPage()
-
-	time.Sleep(10 * time.Second)
-
-	if err = edge.CurrentPage().Keyboard().Press("Escape"); err != nil {
-		log.Printf("按键 escape 失败: %v", err)
-	} else {
-		log.Println("按键 escape 成功")
-	}
-
-	locator, err = edge.WaitForSelector("div.el-pagination > ul.el-pager", 5000)
-	if err != nil {
-		log.Fatalf("等待分页元素失败: %v", err)
-		return 0, err
-	}
-	last_page_str, err := locator.Locator("li:last-child").InnerText()
-	if err != nil {
-		log.Fatalf("无法获取最后一页页码: %v", err)
-		return 0, err
-	}
-	page, err := strconv.Atoi(strings.TrimSpace(last_page_str))
-	if err != nil {
-		log.Fatalf("无法转换页码: %v", err)
-	}
-	return page, nil
+	return search_nmpa(edge, "yp", "境外生产药品", "药")
 }
 
 func next_jinkouyao_page(edge *PlaywrightEdge) error {
 	// 点击下一页
 	locator, err := edge.WaitForSelector("div.el-pagination > button:nth-child(3)", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
-	}
-	return locator.Click()
-}
-
-func wait_for_detail_display(edge *PlaywrightEdge) (playwright.Locator, error) {
-	tbody, err := edge.WaitForSelector("table > tbody", 10000)
-	if err != nil {
-		time.Sleep(1 * time.Second)
-		edge.CurrentPage().Reload()
-		return nil, nil
-	}
-
-	for i := range 5 {
-		registerNo, err := tbody.Locator("tr:nth-child(1) > td:nth-child(2) > div > div").InnerText()
-		if err == nil && registerNo != "" {
-			return tbody, nil
-		}
-		time.Sleep(1 * time.Second)
-		log.Printf("...第 %d 次等待详情页注册证号", i+1)
-	}
-	time.Sleep(10 * time.Second)
-	edge.CurrentPage().Reload()
-
-	registerNo, err := tbody.Locator("tr:nth-child(1) > td:nth-child(2) > div > div").InnerText()
-	if err == nil && registerNo != "" {
-		return tbody, nil
-	}
-	return nil, nil
-}
-
-func collect_jinkouyao_data(edge *PlaywrightEdge) (*MedicineData, error) {
-	var tbody playwright.Locator
-	for i := range 30 {
-		locator, err := wait_for_detail_display(edge)
-		if err != nil {
-			return nil, err
-		}
-		if locator != nil {
-			tbody = locator
-			break
-		}
-		log.Printf("第 %d 次等待详情页显示", i+1)
-	}
-	if tbody == nil {
-		return NewMedicineData(make([]string, 0, 36)), nil
-	}
-
-	items, err := tbody.Locator("tr").All()
-	if err != nil {
-		return nil, err
+		return fmt.Errorf("等待下一页按钮失败: %v", err)
 	}
-	lines := make([]string, 0, 36)
-	for idx, item := range items {
-		if idx > 35 {
-			break
-		}
-		valstr, err := item.Locator("td:nth-child(2)").InnerText()
-		if err != nil {
-			return nil, err
-		}
-		lines = append(lines, valstr)
-	}
-	return NewMedicineData(lines), nil
+	return edge.Click(locator)
 }
 
-func get_page_jinkouyao(edge *PlaywrightEdge, pageNo int) ([]*MedicineData, error) {
-	locator, err := edge.WaitForSelector("table > tbody", 1000)
-	if err != nil {
-		return nil, err
-	}
-	trs, err := locator.Locator("tr").All()
-	if err != nil {
-		return nil, err
-	}
-	log.Printf("第%d页共 %d 条数据", pageNo, len(trs))
-	medicines := make([]*MedicineData, 0, len(trs))
-	for idx, tr := range trs {
-		registerNo, err := tr.Locator("td:nth-child(2) > div > p").InnerText()
-		if err != nil || strings.TrimSpace(registerNo) == "" {
-			log.Printf("第 %d 页第 %d 条数据注册证号为空，跳过", pageNo, idx+1)
-			continue
-		}
-		// 点击详情按钮
-		btn := tr.Locator("td:nth-child(5) > div > button")
-		_, err = edge.OpenNewPage("详情页", func() error {
-			return btn.Click()
-		}, 10000)
-		if err != nil {
-			return nil, err
-		}
-		// 切换到详情页
-		edge.SwitchToNextPage()
-		log.Printf("正在获取第 %d 页第 %d 条数据", pageNo, idx+1)
-		medicine_data, err := collect_jinkouyao_data(edge)
-		if err != nil {
-			return nil, err
-		}
-		if medicine_data != nil {
-			log.Printf("...采集药品 %s %s", medicine_data.ProductNameCN, medicine_data.RegisterNo)
-			medicines = append(medicines, medicine_data)
-		}
-		// 返回列表页
-		edge.SwitchToPreviousPage()
-		// 关闭详情页
-		edge.ClosePage("详情页")
-	}
-	return medicines, nil
+// wait_for_detail_display 等待药监局详情页显示，标签为字段 ready_field 的行有内容时视为加载完成
+func wait_for_detail_display(page playwright.Page, labels *LabelDictionary, ready_field string) (playwright.Locator, error) {
+	return wait_for_detail_table(page, labels, ready_field)
 }
 
 func go_to_page(edge *PlaywrightEdge, pageNo int) error {
 	locator, err := edge.WaitForSelector("div.el-input.el-pagination__editor > input", 1000)
 	if err != nil {
-		log.Fatalf("等待分页元素失败: %v", err)
-		return err
+		return fmt.Errorf("等待分页元素失败: %v", err)
 	}
 	err = locator.Clear()
 	if err != nil {
-		log.Fatalf("无法清空页码输入框: %v", err)
+		return fmt.Errorf("无法清空页码输入框: %v", err)
 	}
 	err = locator.Fill(fmt.Sprint(pageNo))
 	if err != nil {
-		log.Fatalf("无法定位页码: %v", err)
-		return err
+		return fmt.Errorf("无法定位页码: %v", err)
 	}
-	err = locator.Press("Enter")
+	err = edge.Press(locator, "Enter")
 	if err != nil {
-		log.Fatalf("无法跳转到第 %d 页: %v", pageNo, err)
-		return err
+		return fmt.Errorf("无法跳转到第 %d 页: %v", pageNo, err)
 	}
 	locator, err = edge.WaitForSelector("table > tbody", 5000)
 	if err != nil {
-		log.Fatalf("等待表格元素失败: %v", err)
-		return err
+		return fmt.Errorf("等待表格元素失败: %v", err)
 	}
 	return locator.Click()
 }
 
-func clear_local_storage(edge *PlaywrightEdge) error {
-	// ---------- 清除所有存储 ----------
-	page := edge.CurrentPage()
-	// 1. 清除 localStorage 和 sessionStorage
-	if _, err := page.Evaluate("localStorage.clear()"); err != nil {
-		log.Fatalf("清空 localStorage 失败: %v", err)
-	}
-	if _, err := page.Evaluate("sessionStorage.clear()"); err != nil {
-		log.Fatalf("清空 sessionStorage 失败: %v", err)
-	}
-
-	// 2. 清除 Cookies（针对当前域名）
-	if err := edge.context.ClearCookies(); err != nil {
-		log.Fatalf("清除 Cookies 失败: %v", err)
-	}
+// ImportDrugQuery 境外生产药品的查询条件。默认只在首页搜索框输入关键字；
+// Advanced 为 true 时在列表页打开高级搜索，按字段分别查询，否则把字段条件拼接为关键字
+type ImportDrugQuery struct {
+	Keyword       string // 首页搜索框中的关键字
+	ProductName   string // 产品名称（中文）
+	ProductNameEN string // 产品名称（英文）
+	RegisterNo    string // 注册证号
+	Holder        string // 上市许可持有人
+	Manufacturer  string // 生产厂商
+	Advanced      bool   // 使用高级搜索
+}
 
-	// 3. 清除 IndexedDB（通过 JavaScript）
-	if _, err := page.Evaluate(`
-		async () => {
-			const databases = await window.indexedDB.databases();
-			for (const db of databases) {
-				if (db.name) {
-					window.indexedDB.deleteDatabase(db.name);
-				}
-			}
+// DefaultImportDrugQuery 默认以 "药" 为关键字搜索全部境外生产药品
+var DefaultImportDrugQuery = ImportDrugQuery{Keyword: "药"}
+
+// filters 字段条件，标签为高级搜索表单中的文字
+func (q *ImportDrugQuery) filters() []NmpaFilter {
+	filters := []NmpaFilter{}
+	for _, filter := range []NmpaFilter{
+		{Label: "产品名称（中文）", Value: q.ProductName},
+		{Label: "产品名称（英文）", Value: q.ProductNameEN},
+		{Label: "注册证号", Value: q.RegisterNo},
+		{Label: "上市许可持有人", Value: q.Holder},
+		{Label: "生产厂商", Value: q.Manufacturer},
+	} {
+		if filter.Value = strings.TrimSpace(filter.Value); filter.Value != "" {
+			filters = append(filters, filter)
 		}
-	`); err != nil {
-		log.Fatalf("清除 IndexedDB 失败: %v", err)
 	}
-	log.Println("所有存储已清除！")
-
-	return nil
+	return filters
 }
 
-func CollectImportDrugs(output_path string, start_page int, end_page int) {
-	edge, err := NewPlaywrightEdge(0)
-	if err != nil {
-		log.Fatalf("无法启动 Edge 浏览器: %v", err)
-	}
-	defer edge.Close()
-
-	pageCount, err := search_jinkouyao(edge)
-	if err != nil {
-		log.Fatalf("搜索失败: %v", err)
-	}
-	log.Printf("共 %d 页", pageCount)
-	if end_page > pageCount || end_page <= 0 {
-		end_page = pageCount
+// values 非高级搜索时输入首页搜索框的值：自定义关键字和各字段条件
+func (q *ImportDrugQuery) values() []string {
+	values := []string{}
+	if keyword := strings.TrimSpace(q.Keyword); keyword != "" && keyword != DefaultImportDrugQuery.Keyword {
+		values = append(values, keyword)
 	}
-
-	go_to_page(edge, start_page)
-
-	medicines := make([]*MedicineData, 0)
-	for i := start_page; i <= end_page; i++ {
-		log.Printf("正在获取第 %d 页数据", i)
-		data_list, err := get_page_jinkouyao(edge, i)
-		if err != nil {
-			log.Fatalf("获取第 %d 页数据失败: %v", i, err)
-		}
-		medicines = append(medicines, data_list...)
-		log.Printf("第 %d 页数据获取完毕", i)
-		if i == end_page {
-			log.Println("已到达最后一页")
-			break
-		}
-		err = next_jinkouyao_page(edge)
-		if err != nil {
-			log.Printf("跳转到第 %d 页失败: %v", i+1, err)
-			break
-		}
+	for _, filter := range q.filters() {
+		values = append(values, filter.Value)
 	}
-	log.Printf("共 %d 条数据", len(medicines))
+	return values
+}
 
-	excel, err := NewSimpleExcelTableWriter(GetMedicineDataHeaders())
-	if err != nil {
-		log.Fatalf("无法创建 Excel 文件: %v", err)
+// Validate 检查查询条件。首页只有一个搜索框，按空格拼接多个条件会被当作一个关键字模糊匹配，
+// 结果与按字段查询不同，因此非高级搜索时最多只能指定一个条件
+func (q *ImportDrugQuery) Validate() error {
+	if values := q.values(); !q.Advanced && len(values) > 1 {
+		return fmt.Errorf("境外生产药品非高级搜索时只能指定一个查询条件，当前为 %s，多个条件请使用高级搜索", strings.Join(values, ", "))
 	}
-	defer excel.Close()
+	return nil
+}
 
-	for _, medicine := range medicines {
-		err = excel.WriteRow(medicine.ToRowData())
-		if err != nil {
-			log.Fatalf("无法写入 Excel 行: %v", err)
+// NewImportDrugCollector 按查询条件采集境外生产药品，query 需先通过 Validate 检查
+func NewImportDrugCollector(query *ImportDrugQuery) Collector {
+	keyword := strings.TrimSpace(query.Keyword)
+	filters := query.filters()
+	if !query.Advanced {
+		// 首页只有一个搜索框，唯一的条件作为关键字输入
+		if values := query.values(); len(values) > 0 {
+			keyword = values[0]
 		}
+		filters = nil
+	}
+	return &nmpaCategoryCollector{
+		dataset:    "import_drugs",
+		category:   "yp",
+		keyword:    keyword,
+		filters:    filters,
+		title:      "境外生产药品",
+		columns:    nmpaDrugColumns,
+		headers:    GetMedicineDataHeaders(),
+		labels:     medicineDataLabels,
+		readyField: "RegisterNo",
+		newRecord:  func() Record { return &MedicineData{} },
+		summary: func(record Record) string {
+			medicine := record.(*MedicineData)
+			return medicine.ProductNameCN + " " + medicine.RegisterNo
+		},
 	}
-	err = excel.SaveAs(output_path)
-	if err != nil {
-		log.Fatalf("无法保存 Excel 文件: %v", err)
-	}
+}
 
-	err = clear_local_storage(edge)
-	if err != nil {
-		log.Fatalf("清除存储失败: %v", err)
-	}
+func init() {
+	RegisterCollector("import_drugs", func() Collector { return NewImportDrugCollector(&DefaultImportDrugQuery) })
+}
 
+func CollectImportDrugs(output_path string, start_page int, end_page int, run_id string) {
+	CollectDataset("import_drugs", output_path, start_page, end_page, run_id)
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LookupStatus 按注册证号查询的结果
+type LookupStatus string
+
+const (
+	LookupFound    LookupStatus = "found"     // 唯一匹配
+	LookupNotFound LookupStatus = "not_found" // 搜索结果中没有该注册证号
+	LookupMultiple LookupStatus = "multiple"  // 搜索结果中有多条记录的注册证号相同
+	LookupFailed   LookupStatus = "failed"    // 搜索或采集详情失败
+)
+
+// LookupResult 一个注册证号在一个数据源中的查询结果
+type LookupResult struct {
+	Source     string       `json:"source"`
+	RegisterNo string       `json:"register_no"`
+	Status     LookupStatus `json:"status"`
+	Records    []Record     `json:"records,omitempty"` // 匹配行的详情，多条匹配时全部返回
+	Error      string       `json:"error,omitempty"`
+}
+
+// 按注册证号搜索时最多查看的页数，关键字搜索会模糊匹配，精确匹配的记录通常在第 1 页
+const lookup_max_pages = 5
+
+// lookup_sources 数据源名称 -> 按注册证号搜索的采集器
+var lookup_sources = map[string]func(register_no string) Collector{
+	// 药监局境外生产药品，返回 MedicineData
+	"nmpa": func(register_no string) Collector {
+		return NewImportDrugCollector(&ImportDrugQuery{RegisterNo: register_no})
+	},
+	// CDE 上市药品目录集（不限收录类别），返回 OriginalDrug
+	"cde": func(register_no string) Collector {
+		return NewOriginalDrugCollector(&OriginalDrugQuery{AuthCode: register_no})
+	},
+}
+
+// LookupSources 可查询的数据源名称
+func LookupSources() []string {
+	names := make([]string, 0, len(lookup_sources))
+	for name := range lookup_sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRegisterNos 在各数据源中按注册证号（批准文号）逐个搜索，只打开注册证号完全一致的行的详情页。
+// 单个注册证号的失败记录在结果中，不影响其他注册证号
+func LookupRegisterNos(sources []string, register_nos []string) ([]*LookupResult, error) {
+	for _, source := range sources {
+		if _, ok := lookup_sources[source]; !ok {
+			return nil, fmt.Errorf("未知的数据源 %s，可选: %s", source, strings.Join(LookupSources(), ", "))
+		}
+	}
+
+	edge, err := NewPlaywrightEdge(0)
+	if err != nil {
+		return nil, fmt.Errorf("无法启动 Edge 浏览器: %v", err)
+	}
+	defer edge.Close()
+
+	options := &CollectOptions{Retries: 2, RetryDelay: 3 * time.Second}
+	results := []*LookupResult{}
+	for _, source := range sources {
+		for _, register_no := range register_nos {
+			register_no = strings.TrimSpace(register_no)
+			if register_no == "" {
+				continue
+			}
+			log.Printf("在 %s 中查询 %s", source, register_no)
+			driver := &collectorDriver{collector: lookup_sources[source](register_no), options: options, edge: edge}
+			result := &LookupResult{Source: source, RegisterNo: register_no}
+			result.Records, err = driver.lookup(register_no)
+			switch {
+			case err != nil:
+				result.Status = LookupFailed
+				result.Error = err.Error()
+			case len(result.Records) == 0:
+				result.Status = LookupNotFound
+			case len(result.Records) == 1:
+				result.Status = LookupFound
+			default:
+				result.Status = LookupMultiple
+			}
+			log.Printf("%s %s: %s", source, register_no, result.Status)
+			results = append(results, result)
+		}
+	}
+
+	log_retry_stats()
+	if err = edge.ClearLocalData(); err != nil {
+		log.Printf("清除存储失败: %v", err)
+	}
+	return results, nil
+}
+
+// lookup 搜索后逐页查找键与 register_no 一致的行并采集详情；行的 Handle 只在当前页有效，因此每页找到后立即采集
+func (d *collectorDriver) lookup(register_no string) ([]Record, error) {
+	pageCount, err := d.collector.Search(d.edge)
+	if err != nil {
+		d.edge.Diagnose(fmt.Sprintf("搜索 %s 失败", register_no), err)
+		return nil, fmt.Errorf("搜索失败: %v", err)
+	}
+	if pageCount > lookup_max_pages {
+		log.Printf("搜索 %s 共 %d 页，只查看前 %d 页", register_no, pageCount, lookup_max_pages)
+		pageCount = lookup_max_pages
+	}
+
+	records := []Record{}
+	for i := 1; i <= pageCount; i++ {
+		if i > 1 {
+			if err = d.goToPage(i-1, i); err != nil {
+				return records, err
+			}
+		}
+		rows, err := d.listRows(i)
+		if err != nil {
+			return records, fmt.Errorf("获取第 %d 页数据失败: %v", i, err)
+		}
+		for _, row := range rows {
+			if !same_register_no(row.Key, register_no) {
+				continue
+			}
+			record, err := d.fetchDetail(row)
+			if err != nil {
+				return records, fmt.Errorf("采集 %s 失败: %v", row.Key, err)
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// same_register_no 比较注册证号，忽略空白和大小写
+func same_register_no(a string, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), ""), strings.Join(strings.Fields(b), ""))
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"rpa-yjj-api/browser"
+
 	"github.com/sssxyd/go-lts-core"
 )
 
@@ -28,6 +34,40 @@ func dispose() {
 	lts.Dispose()
 }
 
+var (
+	shutdownHooks  = make(map[int]func())
+	shutdownSeq    = 0
+	shutdownLocker sync.Mutex
+)
+
+// registerShutdownHook 注册收到退出信号时执行的函数，返回取消注册的函数
+func registerShutdownHook(hook func()) func() {
+	shutdownLocker.Lock()
+	defer shutdownLocker.Unlock()
+	shutdownSeq++
+	id := shutdownSeq
+	shutdownHooks[id] = hook
+	return func() {
+		shutdownLocker.Lock()
+		defer shutdownLocker.Unlock()
+		delete(shutdownHooks, id)
+	}
+}
+
+func runShutdownHooks() {
+	shutdownLocker.Lock()
+	hooks := make([]func(), 0, len(shutdownHooks))
+	for _, hook := range shutdownHooks {
+		hooks = append(hooks, hook)
+	}
+	shutdownHooks = make(map[int]func())
+	shutdownLocker.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
 func handleShutdown() {
 	// 创建一个 channel 来接收操作系统信号
 	signalChan := make(chan os.Signal, 1)
@@ -39,12 +79,88 @@ func handleShutdown() {
 	sig := <-signalChan
 	log.Printf("Received signal: %s. Shutting down...", sig)
 
+	runShutdownHooks()
 	dispose()
 
 	os.Exit(0)
 }
 
 func main() {
+	launchMode := flag.String("launch", "edge-cdp", "浏览器启动方式: edge-cdp 通过调试端口连接本机 Edge；chromium 由 Playwright 启动 Chromium，支持 Linux")
+	headless := flag.Bool("headless", false, "以无头模式运行，只在 -launch chromium 时有效")
+	harMode := flag.String("har-mode", "off", "网络流量录制/回放方式: off, record, replay")
+	harPath := flag.String("har", "", "HAR 文件路径")
+	resetSession := flag.Bool("reset-session", false, "丢弃上次保存的会话，重新通过站点的反爬预热")
+	runID := flag.String("run", "", "断点续采的运行 ID，为空时开始新的采集")
+	startPage := flag.Int("start", 1, "采集的起始页")
+	endPage := flag.Int("end", 0, "采集的结束页，为 0 时采集到最后一页")
+	format := flag.String("format", "csv", "输出格式: csv 逐行写入；xlsx 记录保存在内存中，只适合记录数不多的采集")
+	flag.IntVar(&detailConcurrency, "concurrency", detailConcurrency, "同时打开的详情页数量，大于 1 时在多个标签页中并发采集")
+	datasetsDir := flag.String("datasets", filepath.Join(get_app_root_dir(), "datasets"), "数据集定义文件所在的目录，启动时加载其中的 .yaml、.yml 和 .json 文件，不包括子目录")
+	dataset := flag.String("dataset", "original_drugs", "采集的数据集: "+strings.Join(CollectorDatasets(), ", ")+"，以及 -datasets 目录中定义的数据集")
+	cdeQuery := DefaultOriginalDrugQuery
+	flag.StringVar(&cdeQuery.Category, "cde-category", cdeQuery.Category, "CDE 上市药品目录集的收录类别，为空时不限")
+	flag.StringVar(&cdeQuery.SalesStatus, "cde-status", "", "CDE 上市药品目录集的上市销售状态，如 上市销售中")
+	flag.StringVar(&cdeQuery.DrugName, "cde-drug", "", "CDE 上市药品目录集的药品名称")
+	flag.StringVar(&cdeQuery.ActiveIngredient, "cde-ingredient", "", "CDE 上市药品目录集的活性成分")
+	flag.StringVar(&cdeQuery.ATCPrefix, "cde-atc", "", "CDE 上市药品目录集的 ATC 码前缀")
+	flag.StringVar(&cdeQuery.Holder, "cde-holder", "", "CDE 上市药品目录集的上市许可持有人")
+	flag.StringVar(&cdeQuery.AuthCode, "cde-auth-code", "", "CDE 上市药品目录集的批准文号/注册证号")
+	drugQuery := DefaultImportDrugQuery
+	flag.StringVar(&drugQuery.Keyword, "keyword", drugQuery.Keyword, "境外生产药品的搜索关键字")
+	flag.StringVar(&drugQuery.ProductName, "product", "", "境外生产药品的产品名称（中文）")
+	flag.StringVar(&drugQuery.ProductNameEN, "product-en", "", "境外生产药品的产品名称（英文）")
+	flag.StringVar(&drugQuery.RegisterNo, "register-no", "", "境外生产药品的注册证号")
+	flag.StringVar(&drugQuery.Holder, "holder", "", "境外生产药品的上市许可持有人")
+	flag.StringVar(&drugQuery.Manufacturer, "manufacturer", "", "境外生产药品的生产厂商")
+	flag.BoolVar(&drugQuery.Advanced, "advanced", false, "境外生产药品使用高级搜索按字段查询")
+	lookup := flag.String("lookup", "", "按注册证号（批准文号）查询单条记录，多个用逗号分隔，指定后不进行采集")
+	lookupSource := flag.String("lookup-source", "nmpa,cde", "查询的数据源，多个用逗号分隔: "+strings.Join(LookupSources(), ", "))
+	lookupOutput := flag.String("lookup-output", "", "查询结果的 JSON 文件路径，为空时输出到标准输出")
+	flag.Parse()
+
+	if err := RegisterDatasetDefinitions(*datasetsDir); err != nil {
+		log.Fatalf("%v", err)
+	}
+	launch, err := browser.ParseLaunchMode(*launchMode)
+	if err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	if *headless && launch != browser.LaunchChromium {
+		log.Fatalf("参数错误: -headless 只能与 -launch chromium 一起使用")
+	}
+	mode, err := browser.ParseHarMode(*harMode)
+	if err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	if *format != "csv" && *format != "xlsx" {
+		log.Fatalf("参数错误: 不支持的输出格式 %s", *format)
+	}
+	if err = drugQuery.Validate(); err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	edgeOptions.Mode = launch
+	edgeOptions.Headless = *headless
+	edgeOptions.HarMode = mode
+	edgeOptions.HarPath = *harPath
+	if *resetSession {
+		browser.InvalidateSession(lts.Storage(), sessionDomains...)
+	}
+	edgeOptions.Session = &browser.SessionOptions{
+		Store:   lts.Storage(),
+		Domains: sessionDomains,
+	}
+	// 回放 HAR 时不访问站点，不需要控制访问节奏
+	if mode != browser.HarReplay {
+		policies, err := LoadSitePolicies(filepath.Join(get_app_root_dir(), "sites.yaml"))
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if siteScheduler, err = browser.NewScheduler(policies, lts.Storage()); err != nil {
+			log.Fatalf("站点访问节奏无效: %v", err)
+		}
+	}
+
 	go handleShutdown()
 
 	defer dispose()
@@ -52,17 +168,47 @@ func main() {
 	root_path := get_app_root_dir()
 	fmt.Println("Root path:", root_path)
 
-	// path := filepath.Join(root_path, "进口原研药列表.xlsx")
-	// YuanYanYao(path)
+	if *lookup != "" {
+		if err = run_lookup(strings.Split(*lookupSource, ","), strings.Split(*lookup, ","), *lookupOutput); err != nil {
+			log.Fatalf("查询失败: %v", err)
+		}
+		return
+	}
 
 	start_time := time.Now()
 	suffix := time.Now().Format("1504")
-	path := filepath.Join(root_path, fmt.Sprintf("进口原研药列表-%s.xlsx", suffix))
-	// CollectImportDrugs(path, 1, 50)
-	CollectOriginalDrugs(path, 7, 9)
+	path := filepath.Join(root_path, fmt.Sprintf("%s-%s.%s", *dataset, suffix, *format))
+	// 指定了查询条件时使用内置采集器，数据集定义文件中的搜索步骤是固定的
+	if *dataset == "original_drugs" && cdeQuery != DefaultOriginalDrugQuery {
+		CollectWith(NewOriginalDrugCollector(&cdeQuery), path, *startPage, *endPage, *runID)
+	} else if *dataset == "import_drugs" && drugQuery != DefaultImportDrugQuery {
+		CollectWith(NewImportDrugCollector(&drugQuery), path, *startPage, *endPage, *runID)
+	} else {
+		CollectDataset(*dataset, path, *startPage, *endPage, *runID)
+	}
 	end_time := time.Now()
 	fmt.Println("Time elapsed:", end_time.Sub(start_time))
 
 	fmt.Println("--------------------")
 
 }
+
+// run_lookup 按注册证号查询并以 JSON 输出结果
+func run_lookup(sources []string, register_nos []string, output_path string) error {
+	for i := range sources {
+		sources[i] = strings.TrimSpace(sources[i])
+	}
+	results, err := LookupRegisterNos(sources, register_nos)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output_path == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	return os.WriteFile(output_path, data, 0644)
+}
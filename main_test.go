@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sssxyd/go-lts-core"
+)
+
+// TestMain 测试改用临时目录中的日志和本地存储，断点等数据不写入应用目录下的 data/storage.db
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "rpa-yjj-api-test")
+	if err != nil {
+		panic(err)
+	}
+	dispose()
+	lts.Initialize(&lts.Options{
+		LogConfig:     lts.LogConfig{MaxAgeDay: 0, StdOut: true, FilePath: filepath.Join(dir, "logs", "app.log")},
+		StorageConfig: lts.StorageConfig{FilePath: filepath.Join(dir, "storage.db")},
+		DBConfigs:     []lts.DBConfig{},
+	})
+	code := m.Run()
+	dispose()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 药监局数据查询首页，category 为药品 yp、医疗器械 ylqx、化妆品 hzp
+const nmpa_home_url = "https://www.nmpa.gov.cn/datasearch/home-index.html#category=%s"
+
+// nmpaListColumns 列表表格中行内元素的选择器，各分类列表的列不同
+type nmpaListColumns struct {
+	key    string // 作为断点键的注册证号（备案编号）
+	detail string // 打开详情页的按钮
+}
+
+// nmpaDrugColumns 药品列表：序号、注册证号、产品名称、企业、详情按钮
+var nmpaDrugColumns = nmpaListColumns{
+	key:    "td:nth-child(2) > div > p",
+	detail: "td:nth-child(5) > div > button",
+}
+
+// nmpaCategoryCollector 药监局数据查询中一个分类的采集器：在首页点击分类、搜索关键字，
+// 列表页为 Element UI 表格和分页，点击每行的详情按钮在新标签页中打开详情表格，按标签取值
+type nmpaCategoryCollector struct {
+	dataset    string
+	category   string       // 首页的分类，如 yp
+	title      string       // 分类链接的标题，如 境外生产药品
+	keyword    string       // 搜索关键字，为空时不输入关键字直接搜索
+	filters    []NmpaFilter // 高级搜索的字段条件，为空时不使用高级搜索
+	columns    nmpaListColumns
+	headers    []string
+	labels     *LabelDictionary
+	readyField string // 详情表格中该字段有内容时视为加载完成，通常为注册证号
+	newRecord  func() Record
+	summary    func(record Record) string // 采集后日志中显示的记录摘要
+}
+
+func (c *nmpaCategoryCollector) Dataset() string {
+	return c.dataset
+}
+
+func (c *nmpaCategoryCollector) Headers() []string {
+	return c.headers
+}
+
+func (c *nmpaCategoryCollector) NewRecord() Record {
+	return c.newRecord()
+}
+
+func (c *nmpaCategoryCollector) Search(edge *PlaywrightEdge) (int, error) {
+	page, err := search_nmpa(edge, c.category, c.title, c.keyword)
+	if err != nil || len(c.filters) == 0 {
+		return page, err
+	}
+	return advanced_search_nmpa(edge, c.filters)
+}
+
+// GoToPage 相邻页点击下一页，否则输入页码跳转；重试时先检查上一次是否已经翻页成功，避免重复点击跳过一页
+func (c *nmpaCategoryCollector) GoToPage(edge *PlaywrightEdge, from int, to int) error {
+	var err error
+	switch nmpa_active_page(edge) {
+	case to:
+		return nil
+	case from:
+		if to == from+1 {
+			err = next_jinkouyao_page(edge)
+		} else {
+			err = go_to_page(edge, to)
+		}
+	default:
+		err = go_to_page(edge, to)
+	}
+	if err != nil {
+		return err
+	}
+	return wait_nmpa_active_page(edge, to)
+}
+
+func (c *nmpaCategoryCollector) ListRows(edge *PlaywrightEdge, page_no int) ([]CollectorRow, error) {
+	locator, err := edge.WaitForSelector("table > tbody", 1000)
+	if err != nil {
+		return nil, err
+	}
+	trs, err := locator.Locator("tr").All()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]CollectorRow, 0, len(trs))
+	for idx, tr := range trs {
+		registerNo, err := tr.Locator(c.columns.key).InnerText()
+		registerNo = strings.TrimSpace(registerNo)
+		if err != nil || registerNo == "" {
+			log.Printf("第 %d 页第 %d 条数据注册证号为空，跳过", page_no, idx+1)
+			continue
+		}
+		rows = append(rows, CollectorRow{
+			Index:  idx + 1,
+			Key:    registerNo,
+			Handle: tr.Locator(c.columns.detail),
+		})
+	}
+	return rows, nil
+}
+
+func (c *nmpaCategoryCollector) FetchDetail(edge *PlaywrightEdge, row CollectorRow) (Record, error) {
+	return collect_detail_tab(edge, c, row)
+}
+
+// OpenDetail 点击行中的详情按钮
+func (c *nmpaCategoryCollector) OpenDetail(row CollectorRow) (func() error, float64) {
+	btn := row.Handle.(playwright.Locator)
+	return func() error {
+		return btn.Click()
+	}, 10000
+}
+
+func (c *nmpaCategoryCollector) ParseDetail(edge *PlaywrightEdge, page playwright.Page) (Record, error) {
+	record := c.newRecord()
+	if err := collect_nmpa_detail(edge, page, c.title, c.labels, c.readyField, record); err != nil {
+		return nil, err
+	}
+	if c.summary != nil {
+		log.Printf("...采集%s %s", c.title, c.summary(record))
+	}
+	return record, nil
+}
+
+// search_nmpa 在药监局数据查询首页点击分类并搜索关键字，切换到搜索结果列表页，返回总页数
+func search_nmpa(edge *PlaywrightEdge, category string, title string, keyword string) (int, error) {
+	// 打开分类首页
+	err := edge.Visit(fmt.Sprintf(nmpa_home_url, category))
+	if err != nil {
+		return 0, err
+	}
+
+	// 等待分类链接显示，首次访问时反爬预热较慢，超时时间较长；显示后关闭可能出现的弹窗
+	locator, err := edge.WaitForSelector(fmt.Sprintf("div.el-col.el-col-8 a[title='%s']", title), 30000)
+	if err != nil {
+		return 0, err
+	}
+	if err = edge.CurrentPage().Keyboard().Press("Escape"); err != nil {
+		log.Printf("按键 escape 失败: %v", err)
+	} else {
+		log.Println("按键 escape 成功")
+	}
+
+	// 点击分类
+	if err = edge.Click(locator); err != nil {
+		return 0, fmt.Errorf("无法点击分类 %s: %w", title, err)
+	}
+
+	// 定位搜索输入框
+	locator, err = edge.WaitForSelector("div.search-input.el-input.el-input-group.el-input-group--append input", 3000)
+	if err != nil {
+		return 0, err
+	}
+	if err = locator.Fill(keyword); err != nil {
+		return 0, fmt.Errorf("无法输入搜索关键字: %w", err)
+	}
+
+	// 回车搜索
+	_, err = edge.OpenNewPage("列表页", func() error {
+		return locator.Press("Enter")
+	}, 3000)
+	if err != nil {
+		return 0, err
+	}
+	// 切换到新页面
+	edge.SwitchToNextPage()
+
+	// 等待列表的分页或无数据提示显示，再关闭可能出现的弹窗
+	if _, err = edge.WaitForSelector(nmpa_list_ready, 30000); err != nil {
+		return 0, fmt.Errorf("等待搜索结果列表失败: %v", err)
+	}
+	if err = edge.CurrentPage().Keyboard().Press("Escape"); err != nil {
+		log.Printf("按键 escape 失败: %v", err)
+	} else {
+		log.Println("按键 escape 成功")
+	}
+
+	page, err := nmpa_page_count(edge)
+	if err != nil {
+		return 0, err
+	}
+
+	// 列表已显示，说明通过了反爬预热，保存会话供下次启动恢复
+	if err = edge.SaveSession(); err != nil {
+		log.Printf("%v", err)
+	}
+	return page, nil
+}
+
+// nmpa_list_ready 搜索结果列表加载完成后显示的分页或无数据提示
+const nmpa_list_ready = "div.el-pagination > ul.el-pager, .el-table__empty-text"
+
+// NmpaFilter 高级搜索的一个字段条件，Label 为搜索表单中的标签文字
+type NmpaFilter struct {
+	Label string
+	Value string
+}
+
+// 高级搜索的入口按钮、对话框、表单项和提交按钮。入口只匹配按钮，以免同时匹配到对话框的标题；
+// 表单项和提交按钮只在对话框中查找，以免匹配到列表页搜索栏中的表单和按钮。
+// 这些选择器是按 Element UI 对话框和表单的通用结构编写的，尚未在线上页面核对，首次使用 -advanced 时需确认，
+// 找不到元素时 advanced_search_nmpa 返回错误而不会退回关键字搜索
+const (
+	nmpa_advanced_button = "button:has-text('高级搜索'), button:has-text('高级检索')"
+	nmpa_advanced_dialog = ".el-dialog:has(.el-form)"
+	nmpa_advanced_field  = nmpa_advanced_dialog + " .el-form-item:has(label:has-text('%s')) input"
+	nmpa_advanced_submit = nmpa_advanced_dialog + " button.el-button--primary"
+)
+
+// advanced_search_nmpa 在搜索结果列表页打开高级搜索，按表单标签填写字段条件后重新搜索，返回总页数
+func advanced_search_nmpa(edge *PlaywrightEdge, filters []NmpaFilter) (int, error) {
+	locator, err := edge.WaitForSelector(nmpa_advanced_button, 5000)
+	if err != nil {
+		return 0, fmt.Errorf("未找到高级搜索按钮: %v", err)
+	}
+	if err = edge.Click(locator); err != nil {
+		return 0, err
+	}
+	if _, err = edge.WaitForSelector(nmpa_advanced_dialog, 3000); err != nil {
+		return 0, fmt.Errorf("高级搜索对话框未显示: %v", err)
+	}
+
+	for _, filter := range filters {
+		locator, err = edge.WaitForSelector(fmt.Sprintf(nmpa_advanced_field, filter.Label), 3000)
+		if err != nil {
+			return 0, fmt.Errorf("高级搜索中没有 %s: %v", filter.Label, err)
+		}
+		if err = locator.Fill(filter.Value); err != nil {
+			return 0, err
+		}
+		log.Printf("高级搜索: %s = %s", filter.Label, filter.Value)
+	}
+
+	locator, err = edge.WaitForSelector(nmpa_advanced_submit, 3000)
+	if err != nil {
+		return 0, fmt.Errorf("未找到高级搜索的搜索按钮: %v", err)
+	}
+	if err = edge.Click(locator); err != nil {
+		return 0, err
+	}
+	edge.WaitForIdle(10 * time.Second)
+	return nmpa_page_count(edge)
+}
+
+// nmpa_page_count 读取列表的总页数，没有数据时返回 0
+func nmpa_page_count(edge *PlaywrightEdge) (int, error) {
+	locator, err := edge.WaitForSelector("div.el-pagination > ul.el-pager", 5000)
+	if err != nil {
+		if _, e := edge.WaitForSelector(".el-table__empty-text", 1000); e == nil {
+			log.Println("没有符合条件的数据")
+			return 0, nil
+		}
+		return 0, fmt.Errorf("等待分页元素失败: %v", err)
+	}
+	last_page_str, err := locator.Locator("li:last-child").InnerText()
+	if err != nil {
+		return 0, fmt.Errorf("无法获取最后一页页码: %v", err)
+	}
+	page, err := strconv.Atoi(strings.TrimSpace(last_page_str))
+	if err != nil {
+		return 0, fmt.Errorf("无法转换页码: %v", err)
+	}
+	return page, nil
+}
+
+// nmpa_active_page Element UI 分页器的当前页，无法读取时返回 0
+func nmpa_active_page(edge *PlaywrightEdge) int {
+	text, err := edge.CurrentPage().Locator("div.el-pagination > ul.el-pager > li.active").First().InnerText(
+		playwright.LocatorInnerTextOptions{Timeout: playwright.Float(1000)})
+	if err != nil {
+		return 0
+	}
+	page, _ := strconv.Atoi(strings.TrimSpace(text))
+	return page
+}
+
+// wait_nmpa_active_page 等待 Element UI 分页器的当前页变为 page_no，避免翻页请求未返回时读到上一页的数据
+func wait_nmpa_active_page(edge *PlaywrightEdge, page_no int) error {
+	return wait_active_page(page_no, func() int { return nmpa_active_page(edge) })
+}
+
+// collect_nmpa_detail 等待详情页 page 显示后按标签给 record 赋值，详情页始终未显示时保存诊断信息并返回错误，
+// 由采集驱动重试或记为失败
+func collect_nmpa_detail(edge *PlaywrightEdge, page playwright.Page, title string, labels *LabelDictionary, ready_field string, record any) error {
+	tbody, err := wait_for_detail_display(page, labels, ready_field)
+	if err != nil {
+		if retryable(err) {
+			edge.DiagnosePage(page, title+"详情页未显示", err)
+		}
+		return fmt.Errorf("%s详情页未显示: %w", title, err)
+	}
+
+	cells, err := ReadDetailCells(tbody, "td:nth-child(1)", "td:nth-child(2)")
+	if err != nil {
+		return err
+	}
+	report, err := labels.Fill(record, cells)
+	if err != nil {
+		return fmt.Errorf("%s详情表格与标签字典不一致: %w", title, err)
+	}
+	if !report.OK() {
+		log.Printf("...%s详情表格与标签字典不一致: %s", title, report)
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"rpa-yjj-api/browser"
+	"rpa-yjj-api/browser/fake"
+	"rpa-yjj-api/retry"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// newFakeEdge 在假浏览器中打开 url，返回包装后的 PlaywrightEdge
+func newFakeEdge(t *testing.T, site *fake.Site, url string) *PlaywrightEdge {
+	t.Helper()
+	edge := newFakeBrowserEdge(t, site)
+	if err := edge.Visit(url); err != nil {
+		t.Fatalf("打开 %s 失败: %v", url, err)
+	}
+	return edge
+}
+
+// newFakeBrowserEdge 用假浏览器创建 PlaywrightEdge，当前页为空白页；翻页和详情页的等待改为 1 毫秒间隔，避免失败用例等待太久
+func newFakeBrowserEdge(t *testing.T, site *fake.Site) *PlaywrightEdge {
+	t.Helper()
+	policies := []*retry.Policy{&activePagePolicy, &detailTablePolicy, &detailReadyPolicy}
+	saved := make([]retry.Policy, len(policies))
+	for i, policy := range policies {
+		saved[i] = *policy
+		policy.Initial, policy.Max, policy.MaxAttempts = time.Millisecond, time.Millisecond, 3
+	}
+	t.Cleanup(func() {
+		for i, policy := range policies {
+			*policy = saved[i]
+		}
+	})
+
+	b := fake.NewBrowser(site)
+	t.Cleanup(func() { b.Close() })
+	edge := &PlaywrightEdge{
+		browser:     b,
+		diagnostics: browser.NewDiagnostics(t.TempDir(), browser.NewRunID()),
+		tabIds:      []string{"default"},
+	}
+	return edge
+}
+
+// fakePager 记录假站点的当前页，翻页行为修改当前页并用 render 重新生成列表页
+type fakePager struct {
+	active int
+	clicks int // 点击下一页和跳页的次数
+	render func(active int) string
+}
+
+// next 点击下一页，stuck 为 true 时模拟翻页请求没有返回，当前页不变
+func (p *fakePager) next(stuck bool) fake.Action {
+	return func(tab *fake.TabPage) error {
+		p.clicks++
+		if !stuck {
+			p.active++
+		}
+		return fake.SetContent(p.render(p.active))(tab)
+	}
+}
+
+// jump 在页码输入框 input 中按回车，跳到输入的页码
+func (p *fakePager) jump(input string) fake.Action {
+	return func(tab *fake.TabPage) error {
+		p.clicks++
+		value, err := tab.Page().Locator(input).InputValue()
+		if err != nil {
+			return err
+		}
+		if p.active, err = strconv.Atoi(value); err != nil {
+			return err
+		}
+		return fake.SetContent(p.render(p.active))(tab)
+	}
+}
+
+const nmpaListURL = "https://www.nmpa.gov.cn/datasearch/search-result.html"
+
+// nmpaListPage 药监局 Element UI 列表页，keys 为每行的注册证号，为空的行没有注册证号
+func nmpaListPage(active int, keys ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div class="el-table"><table><tbody>`)
+	for _, key := range keys {
+		fmt.Fprintf(&sb, `<tr><td>1</td><td><div><p>%s</p></div></td><td>名称</td><td>企业</td><td><div><button>详情 %s</button></div></td></tr>`, key, key)
+	}
+	sb.WriteString(`</tbody></table></div><div class="el-pagination"><button class="btn-prev">上一页</button><ul class="el-pager">`)
+	for i := 1; i <= 9; i++ {
+		if i == active {
+			fmt.Fprintf(&sb, `<li class="number active">%d</li>`, i)
+		} else {
+			fmt.Fprintf(&sb, `<li class="number">%d</li>`, i)
+		}
+	}
+	sb.WriteString(`</ul><button class="btn-next">下一页</button>`)
+	sb.WriteString(`<span class="el-pagination__jump"><div class="el-input el-pagination__editor"><input type="number"></div></span></div></body></html>`)
+	return sb.String()
+}
+
+func TestNmpaGoToPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		active     int // 翻页前分页器的当前页
+		from, to   int
+		stuck      bool // 翻页后当前页不变
+		wantActive int
+		wantClicks int
+		wantErr    bool
+	}{
+		{name: "相邻页点击下一页", active: 1, from: 1, to: 2, wantActive: 2, wantClicks: 1},
+		{name: "非相邻页输入页码跳转", active: 1, from: 1, to: 5, wantActive: 5, wantClicks: 1},
+		{name: "已在目标页时不再点击", active: 2, from: 1, to: 2, wantActive: 2, wantClicks: 0},
+		{name: "当前页与 from 不一致时输入页码跳转", active: 3, from: 1, to: 2, wantActive: 2, wantClicks: 1},
+		{name: "翻页后当前页不变", active: 1, from: 1, to: 2, stuck: true, wantActive: 1, wantClicks: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := &fakePager{active: tt.active, render: func(active int) string { return nmpaListPage(active, "国药准字H1") }}
+			site := fake.NewSite().
+				Page(nmpaListURL, pager.render(tt.active)).
+				OnClick(nmpaListURL, "div.el-pagination > button.btn-next", pager.next(tt.stuck)).
+				OnPress(nmpaListURL, "div.el-pagination__editor > input", "Enter", pager.jump("div.el-pagination__editor > input"))
+			edge := newFakeEdge(t, site, nmpaListURL)
+
+			err := (&nmpaCategoryCollector{}).GoToPage(edge, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GoToPage(%d, %d) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if got := nmpa_active_page(edge); got != tt.wantActive {
+				t.Errorf("当前页 = %d, want %d", got, tt.wantActive)
+			}
+			if pager.clicks != tt.wantClicks {
+				t.Errorf("翻页 %d 次, want %d", pager.clicks, tt.wantClicks)
+			}
+		})
+	}
+}
+
+func TestNmpaListRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		wantKeys  []string
+		wantIndex []int
+	}{
+		{"所有行", []string{"国药准字H1", "国药准字H2"}, []string{"国药准字H1", "国药准字H2"}, []int{1, 2}},
+		{"跳过没有注册证号的行", []string{"国药准字H1", " ", "国药准字H3"}, []string{"国药准字H1", "国药准字H3"}, []int{1, 3}},
+		{"空列表", nil, []string{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := fake.NewSite().Page(nmpaListURL, nmpaListPage(1, tt.keys...))
+			edge := newFakeEdge(t, site, nmpaListURL)
+
+			rows, err := (&nmpaCategoryCollector{columns: nmpaDrugColumns}).ListRows(edge, 1)
+			if err != nil {
+				t.Fatalf("ListRows() error = %v", err)
+			}
+			checkRows(t, rows, tt.wantKeys, tt.wantIndex, "详情 ")
+		})
+	}
+}
+
+// checkRows 检查列表行的键、行号，以及 Handle 指向该行的详情按钮（按钮文字为 prefix + 键）
+func checkRows(t *testing.T, rows []CollectorRow, wantKeys []string, wantIndex []int, prefix string) {
+	t.Helper()
+	keys, index := []string{}, []int{}
+	for _, row := range rows {
+		keys = append(keys, row.Key)
+		index = append(index, row.Index)
+		handle, ok := row.Handle.(playwright.Locator)
+		if !ok {
+			t.Fatalf("第 %d 行的 Handle 类型为 %T", row.Index, row.Handle)
+		}
+		if text, err := handle.InnerText(); err != nil || text != prefix+row.Key {
+			t.Errorf("第 %d 行的详情按钮 = %q, %v, want %q", row.Index, text, err, prefix+row.Key)
+		}
+	}
+	if !slices.Equal(keys, wantKeys) {
+		t.Errorf("键 = %v, want %v", keys, wantKeys)
+	}
+	if !slices.Equal(index, wantIndex) {
+		t.Errorf("行号 = %v, want %v", index, wantIndex)
+	}
+}
+
+const nmpaDetailURL = "https://www.nmpa.gov.cn/datasearch/search-info.html"
+
+// 详情表格的行顺序与标签字典不同，并多出一行字典中没有的标签
+const nmpaDetailPage = `<html><body><table><tbody>
+	<tr><td>产品名称（中文）</td><td>某某片</td></tr>
+	<tr><td>包装规格</td><td>10片/盒</td></tr>
+	<tr><td>注册证号</td><td><div><div>H20000001</div></div></td></tr>
+	<tr><td>上市许可持有人（中文）</td><td>某药业</td></tr>
+</tbody></table></body></html>`
+
+func TestNmpaFetchDetail(t *testing.T) {
+	tests := []struct {
+		name     string
+		detail   string
+		want     string // 采集到的注册证号
+		wantErr  bool
+		wantTabs int // 采集后剩余的标签页
+	}{
+		{name: "按注册证号标签判断详情页已加载", detail: nmpaDetailPage, want: "H20000001", wantTabs: 1},
+		{name: "注册证号为空时详情页未加载", detail: strings.Replace(nmpaDetailPage, "H20000001", " ", 1), wantErr: true, wantTabs: 1},
+		{name: "没有注册证号标签", detail: strings.Replace(nmpaDetailPage, "<td>注册证号</td>", "<td>备案号</td>", 1), wantErr: true, wantTabs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := fake.NewSite().
+				Page(nmpaListURL, nmpaListPage(1, "H20000001")).
+				Page(nmpaDetailURL, tt.detail).
+				OnClick(nmpaListURL, "td:nth-child(5) > div > button", fake.OpenPopup(nmpaDetailURL))
+			edge := newFakeEdge(t, site, nmpaListURL)
+			collector := NewImportDrugCollector(&DefaultImportDrugQuery)
+
+			rows, err := collector.ListRows(edge, 1)
+			if err != nil || len(rows) != 1 {
+				t.Fatalf("ListRows() = %v, %v", rows, err)
+			}
+			record, err := collector.FetchDetail(edge, rows[0])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchDetail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !retryable(err) {
+				t.Errorf("详情页未加载应可重试: %v", err)
+			}
+			if err == nil {
+				medicine := record.(*MedicineData)
+				if medicine.RegisterNo != tt.want || medicine.ProductNameCN != "某某片" || medicine.CertHolderCN != "某药业" {
+					t.Errorf("FetchDetail() = %+v", medicine)
+				}
+			}
+			if got := len(edge.browser.TabPages()); got != tt.wantTabs {
+				t.Errorf("采集后有 %d 个标签页, want %d", got, tt.wantTabs)
+			}
+			if edge.CurrentPage().URL() != nmpaListURL {
+				t.Errorf("采集后当前页为 %s, want 列表页", edge.CurrentPage().URL())
+			}
+		})
+	}
+}
+
+// 并发采集时详情页未显示，只为出错的详情标签页保存诊断信息，不遍历其他正在关闭的标签页
+func TestNmpaFetchDetailsInTabsDiagnose(t *testing.T) {
+	keys := []string{"H20000001", "H20000002", "H20000003"}
+	site := fake.NewSite().
+		Page(nmpaListURL, nmpaListPage(1, keys...)).
+		Page(nmpaDetailURL, strings.Replace(nmpaDetailPage, "H20000001", " ", 1)).
+		OnClick(nmpaListURL, "td:nth-child(5) > div > button", fake.OpenPopup(nmpaDetailURL))
+	edge := newFakeEdge(t, site, nmpaListURL)
+	logs := t.TempDir()
+	edge.diagnostics = browser.NewDiagnostics(logs, "run")
+	collector := NewImportDrugCollector(&DefaultImportDrugQuery)
+	driver := &collectorDriver{collector: collector, options: &CollectOptions{Concurrency: 2}, edge: edge}
+
+	rows, err := collector.ListRows(edge, 1)
+	if err != nil || len(rows) != len(keys) {
+		t.Fatalf("ListRows() = %v, %v", rows, err)
+	}
+	records := driver.fetchDetailsInTabs(collector.(DetailTabCollector), 1, rows)
+	if slices.ContainsFunc(records, func(record Record) bool { return record != nil }) {
+		t.Errorf("fetchDetailsInTabs() = %v, want 全部失败", records)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(logs, "run"))
+	if err != nil {
+		t.Fatalf("读取诊断目录失败: %v", err)
+	}
+	var tabs []string
+	for _, entry := range entries {
+		// 诊断包目录名为 序号-标签页ID-时间
+		parts := strings.Split(entry.Name(), "-")
+		tabs = append(tabs, strings.Join(parts[1:len(parts)-1], "-"))
+	}
+	slices.Sort(tabs)
+	if want := []string{"详情页-1", "详情页-2", "详情页-3"}; !slices.Equal(tabs, want) {
+		t.Errorf("诊断包的标签页 = %v, want %v", tabs, want)
+	}
+}
+
+// nmpaAdvancedPage 带高级搜索对话框的列表页。列表页的搜索栏也是 Element UI 表单，其中有主要按钮，
+// 对话框的标题与入口按钮的文字相同，选择器不限定范围时会匹配到多个元素
+type nmpaAdvancedPage struct {
+	open      bool
+	submitted map[string]string // 提交时对话框中的值，为空时尚未提交
+}
+
+var nmpaAdvancedLabels = []string{"产品名称（中文）", "产品名称（英文）", "注册证号", "上市许可持有人", "生产厂商"}
+
+func (p *nmpaAdvancedPage) render() string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><form class="el-form search-bar">`)
+	sb.WriteString(`<div class="el-form-item"><div class="el-input"><input class="el-input__inner"></div></div>`)
+	sb.WriteString(`<div class="el-form-item"><button class="el-button el-button--primary"><span>搜索</span></button><button class="el-button"><span>高级搜索</span></button></div></form>`)
+	style := ""
+	if !p.open {
+		style = ` style="display: none;"`
+	}
+	fmt.Fprintf(&sb, `<div class="el-dialog__wrapper"%s><div class="el-dialog"><div class="el-dialog__header"><span class="el-dialog__title">高级搜索</span></div><div class="el-dialog__body"><form class="el-form">`, style)
+	for _, label := range nmpaAdvancedLabels {
+		fmt.Fprintf(&sb, `<div class="el-form-item"><label class="el-form-item__label">%s：</label><div class="el-form-item__content"><div class="el-input"><input class="el-input__inner"></div></div></div>`, label)
+	}
+	sb.WriteString(`</form></div><div class="el-dialog__footer"><button class="el-button"><span>取消</span></button><button class="el-button el-button--primary"><span>搜索</span></button></div></div></div>`)
+	last := 9
+	if p.submitted != nil {
+		last = 3
+	}
+	fmt.Fprintf(&sb, `<div class="el-pagination"><ul class="el-pager"><li class="number active">1</li><li class="number">%d</li></ul></div></body></html>`, last)
+	return sb.String()
+}
+
+func (p *nmpaAdvancedPage) site() *fake.Site {
+	return fake.NewSite().
+		Page(nmpaListURL, p.render()).
+		OnClick(nmpaListURL, ".search-bar button:has-text('高级搜索')", func(tab *fake.TabPage) error {
+			p.open = true
+			return fake.SetContent(p.render())(tab)
+		}).
+		OnClick(nmpaListURL, ".el-dialog__footer button.el-button--primary", func(tab *fake.TabPage) error {
+			p.submitted = map[string]string{}
+			for _, label := range nmpaAdvancedLabels {
+				value, err := tab.Page().Locator(fmt.Sprintf(".el-dialog .el-form-item:has(label:text-is('%s：')) input", label)).InputValue()
+				if err != nil {
+					return err
+				}
+				if value != "" {
+					p.submitted[label] = value
+				}
+			}
+			p.open = false
+			return fake.SetContent(p.render())(tab)
+		})
+}
+
+func TestNmpaAdvancedSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ImportDrugQuery
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "按字段查询",
+			query: ImportDrugQuery{ProductName: "阿司匹林肠溶片", RegisterNo: "H20200001", Manufacturer: "Bayer", Advanced: true},
+			want:  map[string]string{"产品名称（中文）": "阿司匹林肠溶片", "注册证号": "H20200001", "生产厂商": "Bayer"},
+		},
+		{
+			name:  "所有字段",
+			query: ImportDrugQuery{ProductName: "片", ProductNameEN: "Tablets", RegisterNo: "H2020", Holder: "某药业", Manufacturer: "某厂", Advanced: true},
+			want:  map[string]string{"产品名称（中文）": "片", "产品名称（英文）": "Tablets", "注册证号": "H2020", "上市许可持有人": "某药业", "生产厂商": "某厂"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &nmpaAdvancedPage{}
+			edge := newFakeEdge(t, page.site(), nmpaListURL)
+
+			pages, err := advanced_search_nmpa(edge, tt.query.filters())
+			if err != nil {
+				t.Fatalf("advanced_search_nmpa() error = %v", err)
+			}
+			if pages != 3 {
+				t.Errorf("总页数 = %d, want 3", pages)
+			}
+			if !maps.Equal(page.submitted, tt.want) {
+				t.Errorf("提交的条件 = %v, want %v", page.submitted, tt.want)
+			}
+		})
+	}
+
+	t.Run("对话框中没有的字段", func(t *testing.T) {
+		page := &nmpaAdvancedPage{}
+		edge := newFakeEdge(t, page.site(), nmpaListURL)
+		_, err := advanced_search_nmpa(edge, []NmpaFilter{{Label: "批准文号", Value: "H20200001"}})
+		if err == nil || !strings.Contains(err.Error(), "高级搜索中没有 批准文号") {
+			t.Errorf("advanced_search_nmpa() error = %v", err)
+		}
+		if page.submitted != nil {
+			t.Error("找不到字段时不应提交")
+		}
+	})
+}
+
+const nmpaHomePage = `<html><body>
+<div class="el-row"><div class="el-col el-col-8"><a title="境外生产药品">境外生产药品</a></div></div>
+<div class="search-input el-input el-input-group el-input-group--append"><input class="el-input__inner"><div class="el-input-group__append"><button>搜索</button></div></div>
+</body></html>`
+
+// 站点当天的操作次数用完后，搜索在下一次点击或回车时停止，不再打开列表页
+func TestSearchNmpaQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name       string
+		quota      int // 打开首页占用 1 次，点击分类占用 1 次
+		wantClicks int
+		wantFill   string
+	}{
+		{name: "点击分类时用完", quota: 1, wantClicks: 0, wantFill: ""},
+		{name: "回车搜索时用完", quota: 2, wantClicks: 1, wantFill: "药"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := siteScheduler
+			t.Cleanup(func() { siteScheduler = saved })
+			scheduler, err := browser.NewScheduler([]browser.SitePolicy{{Domain: "nmpa.gov.cn", DailyQuota: tt.quota}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			siteScheduler = scheduler
+
+			clicks := 0
+			homeURL := fmt.Sprintf(nmpa_home_url, "yp")
+			site := fake.NewSite().
+				Page(homeURL, nmpaHomePage).
+				OnClick(homeURL, "a[title='境外生产药品']", func(tab *fake.TabPage) error {
+					clicks++
+					return nil
+				})
+			edge := newFakeBrowserEdge(t, site)
+
+			_, err = search_nmpa(edge, "yp", "境外生产药品", "药")
+			if !errors.Is(err, browser.ErrQuotaExceeded) {
+				t.Fatalf("search_nmpa() error = %v, want ErrQuotaExceeded", err)
+			}
+			if clicks != tt.wantClicks {
+				t.Errorf("点击分类 %d 次, want %d", clicks, tt.wantClicks)
+			}
+			if value, _ := edge.CurrentPage().Locator("div.search-input input").InputValue(); value != tt.wantFill {
+				t.Errorf("搜索框 = %q, want %q", value, tt.wantFill)
+			}
+			if got := len(edge.browser.TabPages()); got != 1 {
+				t.Errorf("搜索后有 %d 个标签页, want 1", got)
+			}
+		})
+	}
+}
@@ -32,34 +32,28 @@ type OriginalDrug struct {
 	ReviewReport                 string // 审评报告
 }
 
-func NewOriginalDrug(lines []string) *OriginalDrug {
-	if len(lines) < 9 {
-		for i := len(lines); i < 9; i++ {
-			lines = append(lines, "")
-		}
-	}
-	return &OriginalDrug{
-		ActiveIngredients:            lines[0],
-		ActiveIngredientsEN:          lines[1],
-		DrugName:                     lines[2],
-		DrugNameEN:                   lines[3],
-		ProductName:                  lines[4],
-		ProductNameEN:                lines[5],
-		TorchType:                    lines[6],
-		DrugDeliveryRoute:            lines[7],
-		Specification:                lines[8],
-		ReferenceProduct:             lines[9],
-		ATCCode:                      lines[10],
-		AuthCode:                     lines[11],
-		CertDate:                     lines[12],
-		MarketingAuthorizationHolder: lines[13],
-		Manufacturer:                 lines[14],
-		MarketingSalesStatus:         lines[15],
-		Category:                     lines[16],
-		InstructionBook:              lines[17],
-		ReviewReport:                 lines[18],
-	}
-}
+// originalDrugLabels 原研药详情表格的标签，顺序与详情表格的行顺序一致
+var originalDrugLabels = NewLabelDictionary([]LabelField{
+	{Field: "ActiveIngredients", Label: "活性成分"},
+	{Field: "ActiveIngredientsEN", Label: "活性成分（英文）"},
+	{Field: "DrugName", Label: "药品名称"},
+	{Field: "DrugNameEN", Label: "药品名称（英文）"},
+	{Field: "ProductName", Label: "商品名"},
+	{Field: "ProductNameEN", Label: "商品名（英文）"},
+	{Field: "TorchType", Label: "剂型"},
+	{Field: "DrugDeliveryRoute", Label: "给药途径"},
+	{Field: "Specification", Label: "规格"},
+	{Field: "ReferenceProduct", Label: "参比制剂"},
+	{Field: "ATCCode", Label: "ATC码"},
+	{Field: "AuthCode", Label: "批准文号/注册证号", Aliases: []string{"批准文号", "注册证号"}},
+	{Field: "CertDate", Label: "批准日期"},
+	{Field: "MarketingAuthorizationHolder", Label: "上市许可持有人"},
+	{Field: "Manufacturer", Label: "生产厂商"},
+	{Field: "MarketingSalesStatus", Label: "上市销售状态", Aliases: []string{"上市销售状况"}},
+	{Field: "Category", Label: "收录类别"},
+	{Field: "InstructionBook", Label: "说明书"},
+	{Field: "ReviewReport", Label: "审评报告"},
+})
 
 func GetOriginalDrugHeaders() []string {
 	return []string{
@@ -109,251 +103,313 @@ func (medicine *OriginalDrug) ToRowData() []string {
 	}
 }
 
-func od_wait_for_detail_display(edge *PlaywrightEdge) (playwright.Locator, error) {
-	tbody, err := edge.WaitForSelector("table > tbody", 10000)
-	if err != nil {
-		time.Sleep(1 * time.Second)
-		edge.CurrentPage().Reload()
-		return nil, nil
-	}
+// od_wait_for_detail_display 等待原研药详情页显示，批准文号有内容时视为加载完成
+func od_wait_for_detail_display(page playwright.Page) (playwright.Locator, error) {
+	return wait_for_detail_table(page, originalDrugLabels, "AuthCode")
+}
 
-	for i := range 5 {
-		registerNo, err := tbody.Locator("tr:nth-child(12) > td:nth-child(2)").InnerText()
-		if err == nil && registerNo != "" {
-			return tbody, nil
+// od_get_drug_detail 解析详情页 page，edge 用于详情页始终未显示时保存诊断信息
+func od_get_drug_detail(edge *PlaywrightEdge, page playwright.Page) (*OriginalDrug, error) {
+	tbody, err := od_wait_for_detail_display(page)
+	if err != nil {
+		if retryable(err) {
+			edge.DiagnosePage(page, "原研药详情页未显示", err)
 		}
-		time.Sleep(1 * time.Second)
-		log.Printf("...第 %d 次等待详情页注册证号", i+1)
+		return nil, fmt.Errorf("原研药详情页未显示: %w", err)
 	}
-	time.Sleep(10 * time.Second)
-	edge.CurrentPage().Reload()
 
-	registerNo, err := tbody.Locator("tr:nth-child(12) > td:nth-child(2)").InnerText()
-	if err == nil && registerNo != "" {
-		return tbody, nil
+	cells, err := ReadDetailCells(tbody, "td:nth-child(1)", "td:nth-child(2)")
+	if err != nil {
+		return nil, err
+	}
+	medicine := &OriginalDrug{}
+	report, err := originalDrugLabels.Fill(medicine, cells)
+	if err != nil {
+		return nil, fmt.Errorf("原研药详情表格与标签字典不一致: %w", err)
 	}
-	return nil, nil
+	if !report.OK() {
+		log.Printf("...原研药详情表格与标签字典不一致: %s", report)
+	}
+	return medicine, nil
 }
 
-func od_get_drug_detail(edge *PlaywrightEdge) (*OriginalDrug, error) {
-	var tbody playwright.Locator
-	for i := range 30 {
-		locator, err := od_wait_for_detail_display(edge)
-		if err != nil {
-			return nil, err
-		}
-		if locator != nil {
-			tbody = locator
-			break
+// OriginalDrugQuery CDE 上市药品目录集的查询条件，为空的条件不设置
+type OriginalDrugQuery struct {
+	Category         string // 收录类别，如 进口原研药品
+	SalesStatus      string // 上市销售状态，如 上市销售中
+	DrugName         string // 药品名称
+	ActiveIngredient string // 活性成分
+	ATCPrefix        string // ATC码前缀，如 L01
+	Holder           string // 上市许可持有人
+	AuthCode         string // 批准文号/注册证号
+}
+
+// DefaultOriginalDrugQuery 默认只查询进口原研药品
+var DefaultOriginalDrugQuery = OriginalDrugQuery{Category: "进口原研药品"}
+
+func (q *OriginalDrugQuery) String() string {
+	parts := []string{}
+	for _, item := range [][2]string{
+		{"收录类别", q.Category},
+		{"上市销售状态", q.SalesStatus},
+		{"药品名称", q.DrugName},
+		{"活性成分", q.ActiveIngredient},
+		{"ATC码", q.ATCPrefix},
+		{"上市许可持有人", q.Holder},
+		{"批准文号", q.AuthCode},
+	} {
+		if item[1] != "" {
+			parts = append(parts, item[0]+"="+item[1])
 		}
-		log.Printf("第 %d 次等待详情页显示", i+1)
 	}
-	if tbody == nil {
-		return NewOriginalDrug(make([]string, 0, 19)), nil
+	if len(parts) == 0 {
+		return "全部"
 	}
+	return strings.Join(parts, ", ")
+}
 
-	items, err := tbody.Locator("tr").All()
+// od_select_option 按标签文字定位查询表单中的 layui 下拉框，打开后按选项文字选择
+func od_select_option(edge *PlaywrightEdge, label string, text string) error {
+	locator, err := edge.WaitForSelector(fmt.Sprintf("#searchForm div:has(> label:has-text('%s')) .layui-input.layui-unselect", label), 1000)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("未找到查询条件 %s 的下拉框: %v", label, err)
 	}
-	lines := make([]string, 0, 19)
-	for idx, item := range items {
-		if idx > 18 {
-			break
-		}
-		valstr, err := item.Locator("td:nth-child(2)").InnerText()
-		if err != nil {
-			return nil, err
-		}
-		lines = append(lines, valstr)
+	if err = edge.Click(locator); err != nil {
+		return err
 	}
-	return NewOriginalDrug(lines), nil
-}
-
-func od_get_page_data(edge *PlaywrightEdge, pageNo int) ([]*OriginalDrug, error) {
-
-	locator := edge.CurrentPage().Locator(".layui-table-body.layui-table-main tr")
-
-	// 获取所有匹配的 tr 元素
-	trs, err := locator.All()
+	option, err := edge.WaitForSelector(fmt.Sprintf(".layui-unselect.layui-form-select.layui-form-selected dd:text-is('%s')", text), 1000)
 	if err != nil {
-		log.Fatalf("无法获取所有 <tr> 元素: %v", err)
+		return fmt.Errorf("下拉框中没有选项 %s: %v", text, err)
 	}
-	log.Printf("第%d页共 %d 条数据", pageNo, len(trs))
+	return edge.Click(option)
+}
 
-	// 统计行数
-	fmt.Printf("找到 %d 行\n", len(trs))
-	medicines := make([]*OriginalDrug, 0, len(trs))
-	// 遍历每个 <tr> 并读取文本内容
-	for i, tr := range trs {
-		registerNo, err := tr.Locator("td:nth-child(2) > div").InnerText()
-		if err != nil || strings.TrimSpace(registerNo) == "" {
-			log.Printf("第 %d 页第 %d 条数据注册证号为空，跳过", pageNo, i+1)
-			if err != nil {
-				log.Printf("无法获取注册证号: %v", err)
-			}
-			break
-		}
-		// 点击详情
-		btn := tr.Locator("td:nth-child(3) > div > a")
-		_, err = edge.OpenNewPage("详情页", func() error {
-			return btn.Click()
-		}, 30000)
-		if err != nil {
-			return nil, err
-		}
-		// 切换到详情页
-		edge.SwitchToNextPage()
-		log.Printf("正在获取第 %d 页第 %d 条数据", pageNo, i+1)
-		medicine_data, err := od_get_drug_detail(edge)
-		if err != nil {
-			return nil, err
-		}
-		if medicine_data != nil {
-			log.Printf("...采集药品 %s %s", medicine_data.DrugName, medicine_data.AuthCode)
-			medicines = append(medicines, medicine_data)
-		}
-		// 返回列表页
-		edge.SwitchToPreviousPage()
-		// 关闭详情页
-		edge.ClosePage("详情页")
+// od_fill_field 按标签文字定位查询表单中的输入框并填写
+func od_fill_field(edge *PlaywrightEdge, label string, value string) error {
+	locator, err := edge.WaitForSelector(fmt.Sprintf("#searchForm div:has(> label:has-text('%s')) input", label), 1000)
+	if err != nil {
+		return fmt.Errorf("未找到查询条件 %s 的输入框: %v", label, err)
 	}
-	return medicines, nil
+	return locator.Fill(value)
 }
 
-func od_search_medicine(edge *PlaywrightEdge) int {
+func od_search_medicine(edge *PlaywrightEdge, query *OriginalDrugQuery) (int, error) {
+	log.Printf("查询条件: %s", query)
+
 	// 打开页面
-	edge.Visit("https://www.cde.org.cn/hymlj/listpage/9cd8db3b7530c6fa0c86485e563f93c7")
+	if err := edge.Visit("https://www.cde.org.cn/hymlj/listpage/9cd8db3b7530c6fa0c86485e563f93c7"); err != nil {
+		return 0, err
+	}
 
 	// 点击按钮：更多查询条件
 	locator, err := edge.WaitForSelector("#moreBtn", 10000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
-	}
-	locator.Click()
-
-	// 选择：上市销售状况 --> 上市销售中
-	// locator, err = edge.WaitForSelector(".layui-row:nth-child(4) .layui-input.layui-unselect", 1000)
-	// if err != nil {
-	// 	log.Fatalf("等待元素失败: %v", err)
-	// }
-	// locator.Click()
-	// locator, err = edge.WaitForSelector(".layui-unselect.layui-form-select.layui-form-selected dd:nth-child(3)", 1000)
-	// if err != nil {
-	// 	log.Fatalf("等待元素失败: %v", err)
-	// }
-	// locator.Click()
-
-	// 选择：收录类别 --> 进口原研药品
-	locator, err = edge.WaitForSelector(".layui-row:nth-child(5) .layui-input.layui-unselect", 1000)
-	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		return 0, fmt.Errorf("等待更多查询条件按钮失败: %v", err)
 	}
-	locator.Click()
-	locator, err = edge.WaitForSelector(".layui-unselect.layui-form-select.layui-form-selected dd:nth-child(2)", 1000)
-	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+	if err = edge.Click(locator); err != nil {
+		return 0, fmt.Errorf("无法展开更多查询条件: %w", err)
+	}
+
+	// 选择：上市销售状况
+	if query.SalesStatus != "" {
+		if err = od_select_option(edge, "上市销售", query.SalesStatus); err != nil {
+			return 0, fmt.Errorf("无法选择上市销售状态: %w", err)
+		}
+	}
+
+	// 选择：收录类别
+	if query.Category != "" {
+		if err = od_select_option(edge, "收录类别", query.Category); err != nil {
+			return 0, fmt.Errorf("无法选择收录类别: %w", err)
+		}
+	}
+
+	// 填写：药品名称、活性成分、ATC码、上市许可持有人、批准文号
+	for _, field := range [][2]string{
+		{"药品名称", query.DrugName},
+		{"活性成分", query.ActiveIngredient},
+		{"ATC码", query.ATCPrefix},
+		{"上市许可持有人", query.Holder},
+		{"批准文号", query.AuthCode},
+	} {
+		if field[1] == "" {
+			continue
+		}
+		if err = od_fill_field(edge, field[0], field[1]); err != nil {
+			return 0, err
+		}
 	}
-	locator.Click()
 
 	// 点击按钮：查询
 	locator, err = edge.WaitForSelector("#searchForm > .layui-row > div > button:first-child", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		return 0, fmt.Errorf("等待查询按钮失败: %v", err)
 	}
-	locator.Click()
-	time.Sleep(1 * time.Second)
+	if err = edge.Click(locator); err != nil {
+		return 0, fmt.Errorf("无法点击查询按钮: %w", err)
+	}
+	edge.WaitForIdle(10 * time.Second)
 
 	// 点击按钮：收起更多查询条件
 	locator, err = edge.WaitForSelector("#moreBtn", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		return 0, fmt.Errorf("等待更多查询条件按钮失败: %v", err)
+	}
+	if err = edge.Click(locator); err != nil {
+		return 0, fmt.Errorf("无法收起更多查询条件: %w", err)
 	}
-	locator.Click()
-	time.Sleep(1 * time.Second)
 
 	// 设置每页显示 90 条
 	locator, err = edge.WaitForSelector(".layui-laypage-limits select", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		return 0, fmt.Errorf("等待每页条数下拉框失败: %v", err)
 	}
-	locator.SelectOption(playwright.SelectOptionValues{
+	if _, err = locator.SelectOption(playwright.SelectOptionValues{
 		Values: &[]string{"90"},
-	})
-
-	time.Sleep(3 * time.Second)
+	}); err != nil {
+		return 0, fmt.Errorf("无法设置每页条数: %w", err)
+	}
+	edge.WaitForIdle(10 * time.Second)
 	locator, err = edge.WaitForSelector(".layui-laypage-last", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		// 只有一页时没有“最后一页”按钮
+		if _, e := edge.WaitForSelector(".layui-laypage-curr", 1000); e == nil {
+			return 1, nil
+		}
+		return 0, fmt.Errorf("等待最后一页按钮失败: %v", err)
 	}
 	lastpage, err := locator.InnerText()
 	if err != nil {
-		log.Fatalf("无法获取最后一页页码: %v", err)
+		return 0, fmt.Errorf("无法获取最后一页页码: %v", err)
 	}
 	fmt.Printf("最后一页页码: %s\n", lastpage)
 	page, err := strconv.Atoi(strings.TrimSpace(lastpage))
 	if err != nil {
-		log.Fatalf("无法转换页码: %v", err)
+		return 0, fmt.Errorf("无法转换页码: %v", err)
 	}
-	return page
+	return page, nil
 }
 
-func od_next_page(edge *PlaywrightEdge) {
+func od_next_page(edge *PlaywrightEdge) error {
 	locator, err := edge.WaitForSelector(".layui-laypage-next .layui-icon", 1000)
 	if err != nil {
-		log.Fatalf("等待元素失败: %v", err)
+		return fmt.Errorf("等待下一页按钮失败: %v", err)
 	}
-	locator.Click()
+	return edge.Click(locator)
 }
 
-func CollectOriginalDrugs(output_path string, start_page int, end_page int) {
-	// 启动浏览器
-	edge, err := NewPlaywrightEdge(0)
+// od_active_page layui 分页器的当前页，无法读取时返回 0
+func od_active_page(edge *PlaywrightEdge) int {
+	text, err := edge.CurrentPage().Locator(".layui-laypage-curr > em:last-child").First().InnerText(
+		playwright.LocatorInnerTextOptions{Timeout: playwright.Float(1000)})
 	if err != nil {
-		log.Fatalf("无法启动 Edge 浏览器: %v", err)
+		return 0
 	}
-	defer edge.Close()
+	page, _ := strconv.Atoi(strings.TrimSpace(text))
+	return page
+}
 
-	// 搜索进口原研药品
-	total_page := od_search_medicine(edge)
-	fmt.Printf("总页数: %d\n", total_page)
-	if end_page <= 0 || end_page > total_page {
-		end_page = total_page
-	}
-	medicines := make([]*OriginalDrug, 0)
+func init() {
+	RegisterCollector("original_drugs", func() Collector { return NewOriginalDrugCollector(&DefaultOriginalDrugQuery) })
+}
+
+// originalDrugCollector CDE 上市药品目录集，默认采集其中的进口原研药品
+type originalDrugCollector struct {
+	query OriginalDrugQuery
+}
+
+// NewOriginalDrugCollector 按查询条件采集 CDE 上市药品目录集
+func NewOriginalDrugCollector(query *OriginalDrugQuery) Collector {
+	return &originalDrugCollector{query: *query}
+}
+
+func (c *originalDrugCollector) Dataset() string {
+	return "original_drugs"
+}
+
+func (c *originalDrugCollector) Headers() []string {
+	return GetOriginalDrugHeaders()
+}
 
-	for i := 1; i < start_page; i++ {
-		od_next_page(edge)
+func (c *originalDrugCollector) NewRecord() Record {
+	return &OriginalDrug{}
+}
+
+func (c *originalDrugCollector) Search(edge *PlaywrightEdge) (int, error) {
+	return od_search_medicine(edge, &c.query)
+}
+
+// GoToPage 列表只能逐页向后翻，每翻一页等待分页器的当前页变化；
+// 从分页器的当前页而不是 from 开始翻，重试时不会因为上一次已经翻过而多翻一页
+func (c *originalDrugCollector) GoToPage(edge *PlaywrightEdge, from int, to int) error {
+	if to < from {
+		return fmt.Errorf("无法从第 %d 页返回第 %d 页", from, to)
 	}
-	for i := start_page; i <= end_page; i++ {
-		fmt.Printf("正在获取第 %d 页数据\n", i)
-		pageList, err := od_get_page_data(edge, i)
-		if err != nil {
-			log.Fatalf("无法获取第 %d 页数据: %v", i, err)
+	for {
+		active := od_active_page(edge)
+		switch {
+		case active == to:
+			return nil
+		case active == 0:
+			return fmt.Errorf("无法读取分页器的当前页")
+		case active > to:
+			return fmt.Errorf("当前页为第 %d 页，无法返回第 %d 页", active, to)
+		}
+		if err := od_next_page(edge); err != nil {
+			return err
 		}
-		medicines = append(medicines, pageList...)
-		if i < end_page {
-			od_next_page(edge)
+		if err := wait_active_page(active+1, func() int { return od_active_page(edge) }); err != nil {
+			return err
 		}
 	}
+}
 
-	log.Printf("共 %d 条数据", len(medicines))
-	edge.ClearLocalData()
-
-	excel, err := NewSimpleExcelTableWriter(GetOriginalDrugHeaders())
+func (c *originalDrugCollector) ListRows(edge *PlaywrightEdge, page_no int) ([]CollectorRow, error) {
+	trs, err := edge.CurrentPage().Locator(".layui-table-body.layui-table-main tr").All()
 	if err != nil {
-		log.Fatalf("无法创建 Excel 文件: %v", err)
+		return nil, fmt.Errorf("无法获取所有 <tr> 元素: %v", err)
 	}
-	defer excel.Close()
-
-	for _, medicine := range medicines {
-		err = excel.WriteRow(medicine.ToRowData())
-		if err != nil {
-			log.Fatalf("无法写入 Excel 行: %v", err)
+	rows := make([]CollectorRow, 0, len(trs))
+	for i, tr := range trs {
+		registerNo, err := tr.Locator("td:nth-child(2) > div").InnerText()
+		registerNo = strings.TrimSpace(registerNo)
+		if err != nil || registerNo == "" {
+			log.Printf("第 %d 页第 %d 条数据注册证号为空，跳过", page_no, i+1)
+			if err != nil {
+				log.Printf("无法获取注册证号: %v", err)
+			}
+			break
 		}
+		rows = append(rows, CollectorRow{
+			Index:  i + 1,
+			Key:    registerNo,
+			Handle: tr.Locator("td:nth-child(3) > div > a"),
+		})
 	}
-	err = excel.SaveAs(output_path)
+	return rows, nil
+}
+
+func (c *originalDrugCollector) FetchDetail(edge *PlaywrightEdge, row CollectorRow) (Record, error) {
+	return collect_detail_tab(edge, c, row)
+}
+
+// OpenDetail 点击药品名称链接，详情页打开较慢
+func (c *originalDrugCollector) OpenDetail(row CollectorRow) (func() error, float64) {
+	btn := row.Handle.(playwright.Locator)
+	return func() error {
+		return btn.Click()
+	}, 30000
+}
+
+func (c *originalDrugCollector) ParseDetail(edge *PlaywrightEdge, page playwright.Page) (Record, error) {
+	medicine_data, err := od_get_drug_detail(edge, page)
 	if err != nil {
-		log.Fatalf("无法保存 Excel 文件: %v", err)
+		return nil, err
 	}
+	log.Printf("...采集药品 %s %s", medicine_data.DrugName, medicine_data.AuthCode)
+	return medicine_data, nil
+}
+
+func CollectOriginalDrugs(output_path string, start_page int, end_page int, run_id string) {
+	CollectDataset("original_drugs", output_path, start_page, end_page, run_id)
 }
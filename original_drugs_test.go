@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+
+	"rpa-yjj-api/browser/fake"
+)
+
+const odListURL = "https://www.cde.org.cn/hymlj/listpage/9cd8db3b7530c6fa0c86485e563f93c7"
+
+// odListPage CDE 上市药品目录集的 layui 列表页，active 为 0 时没有分页器；keys 为每行的批准文号
+func odListPage(active int, keys ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><div class="layui-table-body layui-table-main"><table><tbody>`)
+	for i, key := range keys {
+		fmt.Fprintf(&sb, `<tr><td><div>%d</div></td><td><div>%s</div></td><td><div><a>查看 %s</a></div></td></tr>`, i+1, key, key)
+	}
+	sb.WriteString(`</tbody></table></div>`)
+	if active > 0 {
+		fmt.Fprintf(&sb, `<div class="layui-laypage"><a class="layui-laypage-prev">上一页</a><span class="layui-laypage-curr"><em class="layui-laypage-em"></em><em>%d</em></span>`, active)
+		sb.WriteString(`<a class="layui-laypage-next"><i class="layui-icon">下一页</i></a></div>`)
+	}
+	sb.WriteString(`</body></html>`)
+	return sb.String()
+}
+
+func TestOriginalDrugGoToPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		active     int
+		from, to   int
+		stuck      bool
+		wantActive int
+		wantClicks int
+		wantErr    bool
+	}{
+		{name: "相邻页", active: 1, from: 1, to: 2, wantActive: 2, wantClicks: 1},
+		{name: "逐页点击到目标页", active: 1, from: 1, to: 4, wantActive: 4, wantClicks: 3},
+		{name: "从当前页而不是 from 开始翻页", active: 3, from: 1, to: 4, wantActive: 4, wantClicks: 1},
+		{name: "已在目标页时不再点击", active: 2, from: 1, to: 2, wantActive: 2, wantClicks: 0},
+		{name: "不能向前翻页", active: 3, from: 3, to: 2, wantActive: 3, wantErr: true},
+		{name: "当前页已超过目标页", active: 5, from: 1, to: 2, wantActive: 5, wantErr: true},
+		{name: "没有分页器", active: 0, from: 1, to: 2, wantActive: 0, wantErr: true},
+		{name: "翻页后当前页不变", active: 1, from: 1, to: 2, stuck: true, wantActive: 1, wantClicks: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := &fakePager{active: tt.active, render: func(active int) string { return odListPage(active, "H20000001") }}
+			site := fake.NewSite().
+				Page(odListURL, pager.render(tt.active)).
+				OnClick(odListURL, ".layui-laypage-next", pager.next(tt.stuck))
+			edge := newFakeEdge(t, site, odListURL)
+
+			err := (&originalDrugCollector{}).GoToPage(edge, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GoToPage(%d, %d) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if got := od_active_page(edge); got != tt.wantActive {
+				t.Errorf("当前页 = %d, want %d", got, tt.wantActive)
+			}
+			if pager.clicks != tt.wantClicks {
+				t.Errorf("翻页 %d 次, want %d", pager.clicks, tt.wantClicks)
+			}
+		})
+	}
+}
+
+func TestOriginalDrugListRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		wantKeys  []string
+		wantIndex []int
+	}{
+		{"所有行", []string{"H20000001", "H20000002"}, []string{"H20000001", "H20000002"}, []int{1, 2}},
+		{"遇到没有批准文号的行时结束", []string{"H20000001", "", "H20000003"}, []string{"H20000001"}, []int{1}},
+		{"空列表", nil, []string{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := fake.NewSite().Page(odListURL, odListPage(1, tt.keys...))
+			edge := newFakeEdge(t, site, odListURL)
+
+			rows, err := (&originalDrugCollector{}).ListRows(edge, 1)
+			if err != nil {
+				t.Fatalf("ListRows() error = %v", err)
+			}
+			checkRows(t, rows, tt.wantKeys, tt.wantIndex, "查看 ")
+		})
+	}
+}
+
+// odSearchPage 模拟 CDE 上市药品目录集的查询表单：下拉框按标签定位，与所在的行无关
+type odSearchPage struct {
+	open      string            // 展开的下拉框的标签
+	selected  map[string]string // 下拉框标签 -> 选中的选项
+	submitted map[string]string // 点击查询时表单中的值，为空时尚未查询
+	more      int               // 点击更多查询条件的次数
+	limit     string            // 每页条数
+}
+
+var odSelectOptions = map[string][]string{
+	"收录类别":   {"进口原研药品", "国内仿制药品"},
+	"上市销售状况": {"上市销售中", "暂停销售"},
+}
+
+var odInputLabels = []string{"药品名称", "活性成分", "ATC码", "上市许可持有人", "批准文号"}
+
+func (p *odSearchPage) render() string {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><button id="moreBtn">更多查询条件</button><form id="searchForm" class="layui-form">`)
+	input := func(label string) {
+		fmt.Fprintf(&sb, `<div class="layui-col-md4"><div class="layui-form-item"><label class="layui-form-label">%s</label><div class="layui-input-block"><input type="text" class="layui-input"></div></div></div>`, label)
+	}
+	selectBox := func(label string) {
+		class := "layui-unselect layui-form-select"
+		if p.open == label {
+			class += " layui-form-selected"
+		}
+		fmt.Fprintf(&sb, `<div class="layui-col-md4"><div class="layui-form-item"><label class="layui-form-label">%s</label><div class="layui-input-block">`, label)
+		fmt.Fprintf(&sb, `<div class="%s"><div class="layui-select-title"><input type="text" readonly value="%s" class="layui-input layui-unselect"></div><dl><dd class="layui-select-tips">请选择</dd>`, class, p.selected[label])
+		for _, option := range odSelectOptions[label] {
+			fmt.Fprintf(&sb, `<dd>%s</dd>`, option)
+		}
+		sb.WriteString(`</dl></div></div></div></div>`)
+	}
+	// 下拉框所在的行与改版前不同
+	sb.WriteString(`<div class="layui-row">`)
+	input("药品名称")
+	input("活性成分")
+	input("ATC码")
+	sb.WriteString(`</div><div class="layui-row">`)
+	selectBox("收录类别")
+	selectBox("上市销售状况")
+	sb.WriteString(`</div><div class="layui-row">`)
+	input("上市许可持有人")
+	input("批准文号")
+	sb.WriteString(`</div><div class="layui-row"><div><button type="button">查询</button><button type="reset">重置</button></div></div></form>`)
+	if p.submitted != nil {
+		sb.WriteString(`<div class="layui-laypage"><span class="layui-laypage-curr"><em>1</em></span><a class="layui-laypage-last">7</a>`)
+		sb.WriteString(`<span class="layui-laypage-limits"><select><option value="10">10 条/页</option><option value="90">90 条/页</option></select></span></div>`)
+	}
+	sb.WriteString(`</body></html>`)
+	return sb.String()
+}
+
+func (p *odSearchPage) site() *fake.Site {
+	site := fake.NewSite().
+		Page(odListURL, p.render()).
+		OnClick(odListURL, "#moreBtn", func(tab *fake.TabPage) error {
+			p.more++
+			return nil
+		}).
+		OnClick(odListURL, "#searchForm > .layui-row > div > button:first-child", func(tab *fake.TabPage) error {
+			p.submitted = maps.Clone(p.selected)
+			for _, label := range odInputLabels {
+				value, err := tab.Page().Locator(fmt.Sprintf("div:has(> label:text-is('%s')) input", label)).InputValue()
+				if err != nil {
+					return err
+				}
+				p.submitted[label] = value
+			}
+			return fake.SetContent(p.render())(tab)
+		}).
+		OnChange(odListURL, ".layui-laypage-limits select", func(tab *fake.TabPage) error {
+			var err error
+			p.limit, err = tab.Page().Locator(".layui-laypage-limits option[selected]").GetAttribute("value")
+			return err
+		})
+	for label, options := range odSelectOptions {
+		site.OnClick(odListURL, fmt.Sprintf("div:has(> label:text-is('%s')) .layui-select-title", label), func(tab *fake.TabPage) error {
+			p.open = label
+			return fake.SetContent(p.render())(tab)
+		})
+		for _, option := range options {
+			site.OnClick(odListURL, fmt.Sprintf("div:has(> label:text-is('%s')) dd:text-is('%s')", label, option), func(tab *fake.TabPage) error {
+				p.open = ""
+				p.selected[label] = option
+				return fake.SetContent(p.render())(tab)
+			})
+		}
+	}
+	return site
+}
+
+func TestOriginalDrugSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   OriginalDrugQuery
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "默认查询条件",
+			query: DefaultOriginalDrugQuery,
+			want:  map[string]string{"收录类别": "进口原研药品", "药品名称": "", "活性成分": "", "ATC码": "", "上市许可持有人": "", "批准文号": ""},
+		},
+		{
+			name:  "按标签选择和填写",
+			query: OriginalDrugQuery{Category: "国内仿制药品", SalesStatus: "暂停销售", DrugName: "阿司匹林", ATCPrefix: "B01", AuthCode: "H20000001"},
+			want: map[string]string{"收录类别": "国内仿制药品", "上市销售状况": "暂停销售",
+				"药品名称": "阿司匹林", "活性成分": "", "ATC码": "B01", "上市许可持有人": "", "批准文号": "H20000001"},
+		},
+		{
+			name:    "下拉框中没有的选项",
+			query:   OriginalDrugQuery{SalesStatus: "已撤市"},
+			wantErr: "无法选择上市销售状态",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &odSearchPage{selected: map[string]string{}}
+			edge := newFakeBrowserEdge(t, page.site())
+
+			pages, err := od_search_medicine(edge, &tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("od_search_medicine() error = %v, want %s", err, tt.wantErr)
+				}
+				if page.submitted != nil {
+					t.Error("选择失败后不应点击查询")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("od_search_medicine() error = %v", err)
+			}
+			if pages != 7 {
+				t.Errorf("总页数 = %d, want 7", pages)
+			}
+			if !maps.Equal(page.submitted, tt.want) {
+				t.Errorf("查询条件 = %v, want %v", page.submitted, tt.want)
+			}
+			if page.more != 2 || page.limit != "90" {
+				t.Errorf("更多查询条件点击 %d 次、每页 %s 条, want 2 次、90 条", page.more, page.limit)
+			}
+		})
+	}
+}
+
+// 打不开查询页面时返回错误，不再继续查找按钮
+func TestOriginalDrugSearchVisitError(t *testing.T) {
+	edge := newFakeBrowserEdge(t, fake.NewSite())
+	if _, err := od_search_medicine(edge, &DefaultOriginalDrugQuery); err == nil || strings.Contains(err.Error(), "moreBtn") || strings.Contains(err.Error(), "更多查询条件") {
+		t.Errorf("od_search_medicine() error = %v, want 打开页面的错误", err)
+	}
+}
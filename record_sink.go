@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record 一条可写入表格的采集记录
+type Record interface {
+	ToRowData() []string
+}
+
+// RecordSink 采集记录的输出，采集器每解析出一条记录就立即写入，
+// Flush 把已写入的记录落盘，Close 落盘后释放资源
+type RecordSink interface {
+	Write(record Record) error
+	Flush() error
+	Close() error
+}
+
+// NewRecordSink 按输出文件的扩展名创建输出：.csv 逐行追加写入，内存占用不随记录数增长，是默认的输出格式；
+// 其余按 Excel 处理，所有记录保存在内存中，每次 Flush 重新保存整个文件，只适合记录数不多的采集。
+// flush_interval 大于 0 时定时落盘，进程收到退出信号时也会落盘
+func NewRecordSink(output_path string, headers []string, flush_interval time.Duration) (RecordSink, error) {
+	var sink RecordSink
+	var err error
+	if strings.EqualFold(filepath.Ext(output_path), ".csv") {
+		sink, err = NewCsvRecordSink(output_path, headers)
+	} else {
+		sink, err = NewExcelRecordSink(output_path, headers)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAutoFlushSink(sink, flush_interval), nil
+}
+
+// CsvRecordSink 以 UTF-8 BOM 开头的 CSV 文件，Excel 可直接打开
+type CsvRecordSink struct {
+	file   *os.File
+	buffer *bufio.Writer
+	writer *csv.Writer
+	count  int
+	locker sync.Mutex
+}
+
+func NewCsvRecordSink(output_path string, headers []string) (*CsvRecordSink, error) {
+	file, err := os.Create(output_path)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建 CSV 文件: %v", err)
+	}
+	buffer := bufio.NewWriter(file)
+	buffer.WriteString("\uFEFF")
+	writer := csv.NewWriter(buffer)
+	if len(headers) > 0 {
+		if err = writer.Write(headers); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("无法写入 CSV 表头: %v", err)
+		}
+	}
+	return &CsvRecordSink{file: file, buffer: buffer, writer: writer}, nil
+}
+
+func (s *CsvRecordSink) Write(record Record) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if err := s.writer.Write(record.ToRowData()); err != nil {
+		return fmt.Errorf("无法写入 CSV 行: %v", err)
+	}
+	s.count++
+	return nil
+}
+
+func (s *CsvRecordSink) Flush() error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return s.flush()
+}
+
+func (s *CsvRecordSink) flush() error {
+	if s.file == nil {
+		return nil
+	}
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return fmt.Errorf("无法写入 CSV 文件: %v", err)
+	}
+	if err := s.buffer.Flush(); err != nil {
+		return fmt.Errorf("无法写入 CSV 文件: %v", err)
+	}
+	return s.file.Sync()
+}
+
+func (s *CsvRecordSink) Close() error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.flush()
+	if e := s.file.Close(); e != nil && err == nil {
+		err = e
+	}
+	log.Printf("已写入 %d 条数据到 %s", s.count, s.file.Name())
+	s.file = nil
+	return err
+}
+
+// ExcelRecordSink 基于 SimpleExcelTableWriter，记录保存在内存中，Flush 时整体另存到输出文件，
+// 内存占用和每次 Flush 的耗时随记录数增长，全量采集请使用 CSV
+type ExcelRecordSink struct {
+	path    string
+	excel   *SimpleExcelTableWriter
+	count   int
+	flushed int // 上次保存时的记录数，没有新记录时跳过保存
+	locker  sync.Mutex
+}
+
+func NewExcelRecordSink(output_path string, headers []string) (*ExcelRecordSink, error) {
+	excel, err := NewSimpleExcelTableWriter(headers)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建 Excel 文件: %v", err)
+	}
+	return &ExcelRecordSink{path: output_path, excel: excel, flushed: -1}, nil
+}
+
+func (s *ExcelRecordSink) Write(record Record) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if err := s.excel.WriteRow(record.ToRowData()); err != nil {
+		return fmt.Errorf("无法写入 Excel 行: %v", err)
+	}
+	s.count++
+	return nil
+}
+
+func (s *ExcelRecordSink) Flush() error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return s.flush()
+}
+
+func (s *ExcelRecordSink) flush() error {
+	if s.excel == nil || s.flushed == s.count {
+		return nil
+	}
+	if err := s.excel.SaveAs(s.path); err != nil {
+		return fmt.Errorf("无法保存 Excel 文件: %v", err)
+	}
+	s.flushed = s.count
+	return nil
+}
+
+func (s *ExcelRecordSink) Close() error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.excel == nil {
+		return nil
+	}
+	err := s.flush()
+	s.excel.Close()
+	s.excel = nil
+	log.Printf("已写入 %d 条数据到 %s", s.count, s.path)
+	return err
+}
+
+// autoFlushSink 定时落盘，并在进程收到退出信号时落盘
+type autoFlushSink struct {
+	RecordSink
+	done       chan struct{}
+	unregister func()
+	once       sync.Once
+}
+
+func newAutoFlushSink(sink RecordSink, interval time.Duration) *autoFlushSink {
+	s := &autoFlushSink{RecordSink: sink, done: make(chan struct{})}
+	s.unregister = registerShutdownHook(func() {
+		if err := sink.Close(); err != nil {
+			log.Printf("退出前保存采集数据失败: %v", err)
+		}
+	})
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := sink.Flush(); err != nil {
+						log.Printf("定时保存采集数据失败: %v", err)
+					}
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	}
+	return s
+}
+
+func (s *autoFlushSink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.unregister()
+	})
+	return s.RecordSink.Close()
+}
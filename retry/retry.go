@@ -0,0 +1,146 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy 一类操作的重试策略：失败后按指数退避加随机抖动等待，重试前可执行恢复步骤（例如刷新页面）
+type Policy struct {
+	Name        string                                                  // 操作名称，用于日志和统计
+	MaxAttempts int                                                     // 最多尝试次数（含第一次），默认为 3
+	MaxElapsed  time.Duration                                           // 从第一次尝试开始的最长时间，超过后不再重试，为 0 时不限
+	Initial     time.Duration                                           // 第一次重试前的等待时间，默认为 1 秒
+	Max         time.Duration                                           // 单次等待时间的上限，默认为 30 秒
+	Multiplier  float64                                                 // 每次重试后等待时间的倍数，默认为 2，为 1 时固定间隔
+	Jitter      float64                                                 // 等待时间随机浮动的比例，取值 0 ~ 1，为 0 时不浮动
+	RetryIf     func(err error) bool                                    // 判断错误是否值得重试，为空时重试所有错误
+	Recover     func(ctx context.Context, attempt int, err error) error // 每次重试前执行的恢复步骤，返回错误时停止重试
+}
+
+// stopError 标记不应重试的错误
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stopError) Unwrap() error {
+	return e.err
+}
+
+// Stop 包装不应重试的错误，Do 遇到后立即返回原错误
+func Stop(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stopError{err}
+}
+
+// Do 按策略执行 fn，attempt 从 1 开始；全部失败时返回最后一次的错误
+func Do(ctx context.Context, policy *Policy, fn func(ctx context.Context, attempt int) error) error {
+	_, err := DoValue(ctx, policy, func(ctx context.Context, attempt int) (struct{}, error) {
+		return struct{}{}, fn(ctx, attempt)
+	})
+	return err
+}
+
+// DoValue 与 Do 相同，成功时返回 fn 的结果
+func DoValue[T any](ctx context.Context, policy *Policy, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	p := policy.withDefaults()
+	stats := statsFor(p.Name)
+	start := time.Now()
+	defer func() {
+		stats.add(func(s *Stats) { s.Elapsed += time.Since(start) })
+	}()
+	stats.add(func(s *Stats) { s.Calls++ })
+
+	var zero T
+	delay := p.Initial
+	for attempt := 1; ; attempt++ {
+		stats.add(func(s *Stats) { s.Attempts++ })
+		value, err := fn(ctx, attempt)
+		if err == nil {
+			stats.add(func(s *Stats) { s.Successes++ })
+			return value, nil
+		}
+
+		var stop *stopError
+		switch {
+		case errors.As(err, &stop):
+			err = stop.err
+		case p.RetryIf != nil && !p.RetryIf(err):
+		case attempt >= p.MaxAttempts:
+		case p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed:
+		default:
+			wait := p.jittered(delay)
+			log.Printf("%s第 %d 次失败，%v 后重试: %v", p.Name, attempt, wait.Round(time.Millisecond), err)
+			if err := sleep(ctx, wait); err != nil {
+				stats.add(func(s *Stats) { s.Failures++ })
+				return zero, err
+			}
+			delay = min(time.Duration(float64(delay)*p.Multiplier), p.Max)
+			if p.Recover != nil {
+				stats.add(func(s *Stats) { s.Recoveries++ })
+				if rerr := p.Recover(ctx, attempt, err); rerr != nil {
+					stats.add(func(s *Stats) { s.Failures++ })
+					return zero, fmt.Errorf("%s恢复失败: %w", p.Name, rerr)
+				}
+			}
+			stats.add(func(s *Stats) { s.Retries++ })
+			continue
+		}
+		stats.add(func(s *Stats) { s.Failures++ })
+		return zero, err
+	}
+}
+
+func (p *Policy) withDefaults() Policy {
+	c := *p
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.Initial <= 0 {
+		c.Initial = 1 * time.Second
+	}
+	if c.Max <= 0 {
+		c.Max = 30 * time.Second
+	}
+	if c.Max < c.Initial {
+		c.Max = c.Initial
+	}
+	if c.Multiplier < 1 {
+		c.Multiplier = 2
+	}
+	c.Jitter = max(0, min(c.Jitter, 1))
+	return c
+}
+
+// jittered 在 delay 上下浮动 Jitter 比例
+func (p *Policy) jittered(delay time.Duration) time.Duration {
+	if p.Jitter == 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+// sleep 重试前的等待，测试中替换为记录等待时间
+var sleep = sleepContext
+
+// sleepContext 等待 d，ctx 取消时提前返回 ctx 的错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,284 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errFailed = errors.New("failed")
+
+// recordSleeps 把等待替换为记录等待时间，测试结束后恢复
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	waits := &[]time.Duration{}
+	sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = sleepContext })
+	return waits
+}
+
+// failing 前 failures 次返回 err，之后成功，返回的计数为执行次数
+func failing(failures int, err error) (func(ctx context.Context, attempt int) error, *int) {
+	calls := 0
+	return func(ctx context.Context, attempt int) error {
+		calls++
+		if attempt != calls {
+			return errors.New("attempt 不连续")
+		}
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestDoBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		failures  int
+		wantCalls int
+		wantWaits []time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "首次成功不等待",
+			policy:    Policy{Name: "backoff-ok"},
+			failures:  0,
+			wantCalls: 1,
+		},
+		{
+			name:      "指数退避",
+			policy:    Policy{Name: "backoff-exp", MaxAttempts: 5, Initial: time.Second},
+			failures:  4,
+			wantCalls: 5,
+			wantWaits: []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:      "等待时间不超过 Max",
+			policy:    Policy{Name: "backoff-max", MaxAttempts: 5, Initial: time.Second, Max: 3 * time.Second},
+			failures:  4,
+			wantCalls: 5,
+			wantWaits: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:      "固定间隔",
+			policy:    Policy{Name: "backoff-fixed", MaxAttempts: 4, Initial: 500 * time.Millisecond, Multiplier: 1},
+			failures:  2,
+			wantCalls: 3,
+			wantWaits: []time.Duration{500 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name:      "达到最多尝试次数后返回最后的错误",
+			policy:    Policy{Name: "backoff-cap", MaxAttempts: 3, Initial: time.Second},
+			failures:  10,
+			wantCalls: 3,
+			wantWaits: []time.Duration{1 * time.Second, 2 * time.Second},
+			wantErr:   true,
+		},
+		{
+			name:      "默认最多尝试 3 次",
+			policy:    Policy{Name: "backoff-default"},
+			failures:  10,
+			wantCalls: 3,
+			wantWaits: []time.Duration{1 * time.Second, 2 * time.Second},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := recordSleeps(t)
+			fn, calls := failing(tt.failures, errFailed)
+			err := Do(context.Background(), &tt.policy, fn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errFailed) {
+				t.Errorf("Do() error = %v, want %v", err, errFailed)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("执行 %d 次, want %d", *calls, tt.wantCalls)
+			}
+			if !slices.Equal(*waits, tt.wantWaits) {
+				t.Errorf("等待 %v, want %v", *waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestDoJitter(t *testing.T) {
+	waits := recordSleeps(t)
+	policy := Policy{Name: "jitter", MaxAttempts: 50, Initial: time.Second, Multiplier: 1, Jitter: 0.2}
+	fn, _ := failing(49, errFailed)
+	if err := Do(context.Background(), &policy, fn); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	for _, wait := range *waits {
+		if wait < 800*time.Millisecond || wait > 1200*time.Millisecond {
+			t.Errorf("等待 %v 超出 1s ± 20%%", wait)
+		}
+	}
+}
+
+func TestDoMaxElapsed(t *testing.T) {
+	policy := Policy{Name: "elapsed", MaxAttempts: 100, MaxElapsed: 50 * time.Millisecond, Initial: 20 * time.Millisecond, Multiplier: 1}
+	fn, calls := failing(100, errFailed)
+	start := time.Now()
+	if err := Do(context.Background(), &policy, fn); !errors.Is(err, errFailed) {
+		t.Fatalf("Do() error = %v, want %v", err, errFailed)
+	}
+	// 下一次等待会超过 MaxElapsed 时不再重试
+	if *calls < 2 || *calls > 3 {
+		t.Errorf("执行 %d 次, want 2 ~ 3", *calls)
+	}
+	if elapsed := time.Since(start); elapsed > policy.MaxElapsed {
+		t.Errorf("耗时 %v 超过 MaxElapsed %v", elapsed, policy.MaxElapsed)
+	}
+}
+
+func TestDoRetryable(t *testing.T) {
+	errQuota := errors.New("quota")
+	retryIf := func(err error) bool { return !errors.Is(err, errQuota) }
+	tests := []struct {
+		name      string
+		err       error
+		retryIf   func(err error) bool
+		wantCalls int
+		wantErr   error
+	}{
+		{"可重试的错误", errFailed, retryIf, 3, errFailed},
+		{"RetryIf 判断为不可重试", errQuota, retryIf, 1, errQuota},
+		{"包装后的不可重试错误", errors.Join(errors.New("visit"), errQuota), retryIf, 1, errQuota},
+		{"Stop 立即返回原错误", Stop(errFailed), nil, 1, errFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordSleeps(t)
+			fn, calls := failing(10, tt.err)
+			policy := Policy{Name: "retryable", MaxAttempts: 3, RetryIf: tt.retryIf}
+			err := Do(context.Background(), &policy, fn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			var stop *stopError
+			if errors.As(err, &stop) {
+				t.Errorf("Do() 返回的错误仍是 Stop 包装: %v", err)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("执行 %d 次, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+	if Stop(nil) != nil {
+		t.Error("Stop(nil) != nil")
+	}
+}
+
+func TestDoContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := Policy{Name: "canceled", MaxAttempts: 5, Initial: time.Minute}
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, &policy, func(ctx context.Context, attempt int) error {
+			calls++
+			return errFailed
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+		}
+		if calls != 1 {
+			t.Errorf("执行 %d 次, want 1", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx 取消后 Do 没有返回")
+	}
+}
+
+func TestDoRecover(t *testing.T) {
+	recordSleeps(t)
+	errReload := errors.New("reload")
+	tests := []struct {
+		name      string
+		recover   func(ctx context.Context, attempt int, err error) error
+		wantCalls int
+		wantErr   error
+	}{
+		{"恢复后重试成功", func(ctx context.Context, attempt int, err error) error { return nil }, 3, nil},
+		{"恢复失败时停止重试", func(ctx context.Context, attempt int, err error) error { return errReload }, 1, errReload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recovered := []int{}
+			policy := Policy{Name: "recover", MaxAttempts: 3, Recover: func(ctx context.Context, attempt int, err error) error {
+				if !errors.Is(err, errFailed) {
+					t.Errorf("恢复步骤收到的错误 = %v, want %v", err, errFailed)
+				}
+				recovered = append(recovered, attempt)
+				return tt.recover(ctx, attempt, err)
+			}}
+			fn, calls := failing(2, errFailed)
+			err := Do(context.Background(), &policy, fn)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("执行 %d 次, want %d", *calls, tt.wantCalls)
+			}
+			if recovered[0] != 1 {
+				t.Errorf("恢复步骤的 attempt = %v, want 从 1 开始", recovered)
+			}
+		})
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	recordSleeps(t)
+	got, err := DoValue(context.Background(), &Policy{Name: "value"}, func(ctx context.Context, attempt int) (int, error) {
+		if attempt < 2 {
+			return -1, errFailed
+		}
+		return attempt * 10, nil
+	})
+	if err != nil || got != 20 {
+		t.Errorf("DoValue() = %d, %v, want 20, nil", got, err)
+	}
+	got, err = DoValue(context.Background(), &Policy{Name: "value", MaxAttempts: 1}, func(ctx context.Context, attempt int) (int, error) {
+		return 5, errFailed
+	})
+	if !errors.Is(err, errFailed) || got != 0 {
+		t.Errorf("DoValue() = %d, %v, want 0, %v", got, err, errFailed)
+	}
+}
+
+func TestStats(t *testing.T) {
+	recordSleeps(t)
+	Reset()
+	t.Cleanup(Reset)
+	policy := Policy{Name: "stats", MaxAttempts: 3, Recover: func(ctx context.Context, attempt int, err error) error { return nil }}
+	ok, _ := failing(1, errFailed)
+	Do(context.Background(), &policy, ok)
+	fail, _ := failing(10, errFailed)
+	Do(context.Background(), &policy, fail)
+
+	snapshot := Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Snapshot() = %v, want 1 项", snapshot)
+	}
+	got := snapshot[0]
+	got.Elapsed = 0
+	want := Stats{Name: "stats", Calls: 2, Attempts: 5, Retries: 3, Recoveries: 3, Successes: 1, Failures: 1}
+	if got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats 一类操作的重试统计，按 Policy.Name 汇总
+type Stats struct {
+	Name       string
+	Calls      int           // 调用 Do 的次数
+	Attempts   int           // 执行操作的总次数
+	Retries    int           // 重试次数
+	Recoveries int           // 执行恢复步骤的次数
+	Successes  int           // 最终成功的调用次数
+	Failures   int           // 最终失败的调用次数
+	Elapsed    time.Duration // 包括等待在内的总耗时
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%s: 调用 %d 次，尝试 %d 次，重试 %d 次，恢复 %d 次，成功 %d 次，失败 %d 次，耗时 %v",
+		s.Name, s.Calls, s.Attempts, s.Retries, s.Recoveries, s.Successes, s.Failures, s.Elapsed.Round(time.Second))
+}
+
+type statsEntry struct {
+	stats  Stats
+	locker sync.Mutex
+}
+
+func (e *statsEntry) add(update func(s *Stats)) {
+	e.locker.Lock()
+	defer e.locker.Unlock()
+	update(&e.stats)
+}
+
+var (
+	registry       = map[string]*statsEntry{}
+	registryLocker sync.Mutex
+)
+
+func statsFor(name string) *statsEntry {
+	registryLocker.Lock()
+	defer registryLocker.Unlock()
+	entry, ok := registry[name]
+	if !ok {
+		entry = &statsEntry{stats: Stats{Name: name}}
+		registry[name] = entry
+	}
+	return entry
+}
+
+// Snapshot 返回各类操作的统计，按名称排序
+func Snapshot() []Stats {
+	registryLocker.Lock()
+	entries := make([]*statsEntry, 0, len(registry))
+	for _, entry := range registry {
+		entries = append(entries, entry)
+	}
+	registryLocker.Unlock()
+
+	result := make([]Stats, 0, len(entries))
+	for _, entry := range entries {
+		entry.locker.Lock()
+		result = append(result, entry.stats)
+		entry.locker.Unlock()
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Reset 清空统计
+func Reset() {
+	registryLocker.Lock()
+	defer registryLocker.Unlock()
+	registry = map[string]*statsEntry{}
+}
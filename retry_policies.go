@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"rpa-yjj-api/browser"
+	"rpa-yjj-api/retry"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 各采集步骤的重试策略，翻页和采集详情的次数与间隔由 CollectOptions 决定，见 collectPolicy
+var (
+	// listRowsPolicy 读取列表行，列表刚翻页时可能还未渲染
+	listRowsPolicy = retry.Policy{Name: "读取列表", MaxAttempts: 3, Initial: 1 * time.Second, Jitter: 0.2, RetryIf: retryable}
+	// detailTablePolicy 等待详情表格显示，每次失败后刷新详情页；每次尝试最长约 15 秒，一行最多占用约 1 分钟，
+	// 仍未显示时交给采集驱动记为失败，不阻塞后续行
+	detailTablePolicy = retry.Policy{Name: "等待详情页", MaxAttempts: 4, MaxElapsed: 1 * time.Minute, Initial: 2 * time.Second, Max: 15 * time.Second, Jitter: 0.2, RetryIf: retryable}
+	// detailReadyPolicy 详情表格显示后等待内容加载，不刷新页面
+	detailReadyPolicy = retry.Policy{Name: "等待详情内容", MaxAttempts: 5, Initial: 1 * time.Second, Multiplier: 1}
+	// activePagePolicy 翻页后等待分页器的当前页变化
+	activePagePolicy = retry.Policy{Name: "等待翻页", MaxAttempts: 10, Initial: 1 * time.Second, Multiplier: 1}
+)
+
+// retryable 站点当天的操作次数用完时，重试没有意义
+func retryable(err error) bool {
+	return !errors.Is(err, browser.ErrQuotaExceeded)
+}
+
+// collectPolicy 由采集参数生成翻页和采集详情的重试策略
+func collectPolicy(name string, options *CollectOptions) *retry.Policy {
+	return &retry.Policy{
+		Name:        name,
+		MaxAttempts: options.Retries + 1,
+		Initial:     options.RetryDelay,
+		Jitter:      0.2,
+		RetryIf:     retryable,
+	}
+}
+
+// reload_page 重试前刷新页面的恢复步骤，刷新按站点访问节奏排队，刷新本身失败时继续重试
+func reload_page(page playwright.Page) func(ctx context.Context, attempt int, err error) error {
+	return func(ctx context.Context, attempt int, err error) error {
+		if err := siteScheduler.Wait(ctx, page.URL()); err != nil {
+			return err
+		}
+		log.Printf("...刷新页面 %s", page.URL())
+		if _, err := page.Reload(); err != nil {
+			log.Printf("刷新页面失败: %v", err)
+		}
+		return nil
+	}
+}
+
+// wait_for_detail_table 等待详情页 page 的表格显示，且表格中标签为字段 ready_field 的行有内容，
+// 按标签而不是行号判断，表格增删行不影响；未显示时刷新页面后重试
+func wait_for_detail_table(page playwright.Page, labels *LabelDictionary, ready_field string) (playwright.Locator, error) {
+	policy := detailTablePolicy
+	policy.Recover = reload_page(page)
+	return retry.DoValue(context.Background(), &policy, func(ctx context.Context, attempt int) (playwright.Locator, error) {
+		tbody, err := wait_for_selector(page, "table > tbody", 10000)
+		if err != nil {
+			return nil, err
+		}
+		if err = wait_for_label(ctx, tbody, labels, ready_field, &detailReadyPolicy); err != nil {
+			return nil, err
+		}
+		return tbody, nil
+	})
+}
+
+// wait_for_label 按策略等待详情表格 tbody 中字段 field 对应标签的行出现且值不为空
+func wait_for_label(ctx context.Context, tbody playwright.Locator, labels *LabelDictionary, field string, policy *retry.Policy) error {
+	return retry.Do(ctx, policy, func(ctx context.Context, attempt int) error {
+		cells, err := ReadDetailCells(tbody, "td:nth-child(1)", "td:nth-child(2)")
+		if err != nil {
+			return err
+		}
+		if value, ok := labels.Lookup(cells, field); !ok || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("详情页内容未加载: %s 为空", field)
+		}
+		return nil
+	})
+}
+
+// wait_for_text 按策略等待元素的文本不为空
+func wait_for_text(ctx context.Context, locator playwright.Locator, policy *retry.Policy) error {
+	return retry.Do(ctx, policy, func(ctx context.Context, attempt int) error {
+		text, err := locator.InnerText(playwright.LocatorInnerTextOptions{Timeout: playwright.Float(1000)})
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("详情页内容未加载")
+		}
+		return nil
+	})
+}
+
+// wait_active_page 按 activePagePolicy 等待分页器的当前页 active() 变为 page_no
+func wait_active_page(page_no int, active func() int) error {
+	return retry.Do(context.Background(), &activePagePolicy, func(ctx context.Context, attempt int) error {
+		if current := active(); current != page_no {
+			return fmt.Errorf("翻页后当前页为第 %d 页，不是第 %d 页", current, page_no)
+		}
+		return nil
+	})
+}
+
+// log_retry_stats 输出各步骤的重试统计
+func log_retry_stats() {
+	for _, stats := range retry.Snapshot() {
+		log.Printf("重试统计 %s", stats)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"rpa-yjj-api/browser"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSitePolicies 各站点默认的访问节奏，可由应用目录下的 sites.yaml 覆盖
+var defaultSitePolicies = []browser.SitePolicy{
+	{Domain: "nmpa.gov.cn", MinInterval: 3 * time.Second, Jitter: 4 * time.Second, DailyQuota: 3000},
+	{Domain: "cde.org.cn", MinInterval: 2 * time.Second, Jitter: 3 * time.Second, DailyQuota: 2000},
+}
+
+// siteScheduler 所有浏览器共用的访问节奏调度器，启动采集前由 main 创建，为 nil 时不限制
+var siteScheduler *browser.Scheduler
+
+// LoadSitePolicies 读取站点访问节奏文件（策略列表），文件中的站点替换同名的默认策略，文件不存在时使用默认策略
+func LoadSitePolicies(path string) ([]browser.SitePolicy, error) {
+	policies := append([]browser.SitePolicy{}, defaultSitePolicies...)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return policies, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取站点访问节奏 %s: %v", path, err)
+	}
+
+	var overrides []browser.SitePolicy
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("无法解析站点访问节奏 %s: %v", path, err)
+	}
+	for _, override := range overrides {
+		replaced := false
+		for i := range policies {
+			if strings.EqualFold(policies[i].Domain, override.Domain) {
+				policies[i] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			policies = append(policies, override)
+		}
+	}
+	return policies, nil
+}